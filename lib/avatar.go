@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// GetAvatar downloads an avatar image such as Developer.Avatar or
+// Contributor.AvatarURL, returning its bytes and content type. The token is
+// not sent, since avatars are public and usually served from another host.
+func (client *Client) GetAvatar(avatarURL string) ([]byte, string, error) {
+	return client.GetAvatarContext(context.Background(), avatarURL)
+}
+
+func (client *Client) GetAvatarContext(ctx context.Context, avatarURL string) ([]byte, string, error) {
+	if avatarURL == "" {
+		return nil, "", errors.New("avatar url must not be empty")
+	}
+	req, err := client.newRequestAccept(ctx, avatarURL, "image/*")
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Del("Authorization")
+	resp, body, err := client.do(req, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	return body, contentType, nil
+}
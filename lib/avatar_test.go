@@ -0,0 +1,52 @@
+package lib_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestGetAvatar(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	var headers http.Header
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	server.Handle("/u/1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(png)
+	}))
+	server.Handle("/u/2", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		w.Write(png)
+	}))
+	client.Token = "secret"
+
+	body, contentType, err := client.GetAvatar(server.URL + "/u/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, png) || contentType != "image/png" {
+		t.Errorf("GetAvatar = %q, %q; want the image bytes and image/png", body, contentType)
+	}
+	if got := headers.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want the token withheld", got)
+	}
+	if got := headers.Get("Accept"); got != "image/*" {
+		t.Errorf("Accept = %q, want image/*", got)
+	}
+
+	if _, contentType, err := client.GetAvatar(server.URL + "/u/2"); err != nil || contentType != "image/png" {
+		t.Errorf("sniffed content type = %q, %v; want image/png", contentType, err)
+	}
+	client.MaxResponseBytes = 4
+	if _, _, err := client.GetAvatar(server.URL + "/u/1"); !errors.Is(err, lib.ErrResponseTooLarge) {
+		t.Errorf("err = %v, want ErrResponseTooLarge", err)
+	}
+	if _, _, err := client.GetAvatar(""); err == nil {
+		t.Error("empty URL: want an error")
+	}
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+func forEachConcurrently(ctx context.Context, n int, concurrency int, fn func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func (client *Client) GetReadmes(items []Item, concurrency int) (map[string]*Readme, map[string]error) {
+	return client.GetReadmesContext(context.Background(), items, concurrency)
+}
+
+func (client *Client) GetReadmesContext(ctx context.Context, items []Item, concurrency int) (map[string]*Readme, map[string]error) {
+	readmes := make(map[string]*Readme)
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	forEachConcurrently(ctx, len(items), concurrency, func(i int) {
+		name := items[i].GetRepositoryName()
+		readme, err := client.GetReadmeContext(ctx, items[i])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[name] = err
+			return
+		}
+		readmes[name] = readme
+	})
+	if ctx.Err() != nil {
+		for i := range items {
+			name := items[i].GetRepositoryName()
+			if _, ok := readmes[name]; !ok {
+				if _, ok := errs[name]; !ok {
+					errs[name] = ctx.Err()
+				}
+			}
+		}
+	}
+	return readmes, errs
+}
+
+func (client *Client) GetRepositories(refs []string, concurrency int) (*Result, map[string]error) {
+	return client.GetRepositoriesContext(context.Background(), refs, concurrency)
+}
+
+// GetRepositoriesContext fetches "owner/name" refs concurrently. The Result
+// keeps the order of refs; failed refs are reported in the error map.
+func (client *Client) GetRepositoriesContext(ctx context.Context, refs []string, concurrency int) (*Result, map[string]error) {
+	items := make([]*Item, len(refs))
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	forEachConcurrently(ctx, len(refs), concurrency, func(i int) {
+		var item *Item
+		owner, name, err := splitRepositoryRef(refs[i])
+		if err == nil {
+			item, err = client.GetRepositoryContext(ctx, owner, name)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[refs[i]] = err
+			return
+		}
+		items[i] = item
+	})
+	result := &Result{Items: []Item{}}
+	for i, item := range items {
+		if item != nil {
+			result.Items = append(result.Items, *item)
+			continue
+		}
+		if _, ok := errs[refs[i]]; !ok && ctx.Err() != nil {
+			errs[refs[i]] = ctx.Err()
+		}
+	}
+	return result, errs
+}
+
+var trendingWindows = []string{"daily", "weekly", "monthly"}
+
+func (client *Client) GetTrendingRepositorySince(language string) (map[string]*Result, map[string]error) {
+	return client.GetTrendingRepositorySinceContext(context.Background(), language)
+}
+
+// GetTrendingRepositorySinceContext fetches the daily, weekly and monthly
+// trending repositories concurrently, keyed by window. Failed windows are
+// reported in the error map.
+func (client *Client) GetTrendingRepositorySinceContext(ctx context.Context, language string) (map[string]*Result, map[string]error) {
+	results := make(map[string]*Result)
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	forEachConcurrently(ctx, len(trendingWindows), len(trendingWindows), func(i int) {
+		since := trendingWindows[i]
+		result, err := client.GetTrendingRepositoryContext(ctx, language, since)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[since] = err
+			return
+		}
+		results[since] = result
+	})
+	if ctx.Err() != nil {
+		for _, since := range trendingWindows {
+			if _, ok := results[since]; !ok {
+				if _, ok := errs[since]; !ok {
+					errs[since] = ctx.Err()
+				}
+			}
+		}
+	}
+	return results, errs
+}
+
+func splitRepositoryRef(ref string) (string, string, error) {
+	owner, name := splitRepositoryName(normalizeRepositoryName(ref))
+	if owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid repository %q: must be owner/name", ref)
+	}
+	return owner, name, nil
+}
@@ -0,0 +1,119 @@
+package lib_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+// concurrencyCounter wraps handlers, recording the most requests that were
+// in flight at the same time.
+type concurrencyCounter struct {
+	inFlight int32
+	max      int32
+}
+
+func (counter *concurrencyCounter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&counter.inFlight, 1)
+		defer atomic.AddInt32(&counter.inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&counter.max)
+			if n <= max || atomic.CompareAndSwapInt32(&counter.max, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestGetReadmesBoundsConcurrency(t *testing.T) {
+	const concurrency = 3
+	counter := &concurrencyCounter{}
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	items := []lib.Item{}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("owner/repo%d", i)
+		items = append(items, lib.Item{FullName: name})
+		server.Handle("/repos/"+name+"/readme", counter.wrap(jsonBody(`{"path":"README.md","encoding":"none","content":"`+name+`"}`)))
+	}
+	server.Handle("/repos/owner/repo7/readme", counter.wrap(http.NotFoundHandler()))
+
+	readmes, errs := client.GetReadmes(items, concurrency)
+	if counter.max > concurrency {
+		t.Errorf("%d requests in flight, want at most %d", counter.max, concurrency)
+	}
+	if len(readmes) != 9 || readmes["owner/repo3"].Content != "owner/repo3" {
+		t.Errorf("readmes = %v", readmes)
+	}
+	if len(errs) != 1 || !lib.IsNotFound(errs["owner/repo7"]) {
+		t.Errorf("errs = %v, want a not found error for owner/repo7", errs)
+	}
+}
+
+func TestGetRepositories(t *testing.T) {
+	const concurrency = 2
+	counter := &concurrencyCounter{}
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	refs := []string{"owner/repo0", "owner/repo1", "owner/missing", "owner/repo2", "not-a-ref", "owner / repo3"}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("owner/repo%d", i)
+		server.Handle("/repos/"+name, counter.wrap(jsonBody(`{"full_name":"`+name+`"}`)))
+	}
+	server.Handle("/repos/owner/missing", counter.wrap(http.NotFoundHandler()))
+
+	result, errs := client.GetRepositories(refs, concurrency)
+	if counter.max > concurrency {
+		t.Errorf("%d requests in flight, want at most %d", counter.max, concurrency)
+	}
+	if want := []string{"owner/repo0", "owner/repo1", "owner/repo2", "owner/repo3"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("items = %v, want %v in ref order", names(result.Items), want)
+	}
+	if len(errs) != 2 || !lib.IsNotFound(errs["owner/missing"]) || errs["not-a-ref"] == nil {
+		t.Errorf("errs = %v, want not found for owner/missing and an error for not-a-ref", errs)
+	}
+}
+
+func TestGetTrendingRepositorySince(t *testing.T) {
+	var languages []string
+	var mu sync.Mutex
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			languages = append(languages, r.URL.Query().Get("lang"))
+			mu.Unlock()
+			switch since := r.URL.Query().Get("since"); since {
+			case "monthly":
+				http.Error(w, "Application Error", http.StatusServiceUnavailable)
+			default:
+				jsonBody(`{"items":[{"repo_link":"https://github.com/owner/`+since+`"}]}`).ServeHTTP(w, r)
+			}
+		}),
+	})
+	defer server.Close()
+
+	results, errs := client.GetTrendingRepositorySince("go")
+	if len(results) != 2 || len(results["daily"].Items) != 1 || len(results["weekly"].Items) != 1 {
+		t.Fatalf("results = %v, want daily and weekly", results)
+	}
+	if got := results["weekly"].Items[0].GetRepositoryName(); got != "owner/weekly" {
+		t.Errorf("weekly item = %q, want owner/weekly", got)
+	}
+	var apiError *lib.APIError
+	if len(errs) != 1 || !errors.As(errs["monthly"], &apiError) || apiError.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("errs = %v, want a 503 for monthly", errs)
+	}
+	if want := []string{"go", "go", "go"}; !reflect.DeepEqual(languages, want) {
+		t.Errorf("languages = %v, want %v", languages, want)
+	}
+}
@@ -0,0 +1,50 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+var ErrEmptyRepositoryURL = errors.New("repository has no URL")
+
+func browserCommand(goos string, url string) (string, []string, error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "xdg-open", []string{url}, nil
+	case "darwin":
+		return "open", []string{url}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}, nil
+	default:
+		return "", nil, fmt.Errorf("opening a browser is not supported on %s", goos)
+	}
+}
+
+// startCommand starts name without waiting for it, since some browsers keep
+// the launching process alive until they exit.
+func startCommand(name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is not installed: %w", name, err)
+	}
+	return exec.Command(name, args...).Start()
+}
+
+func (item *Item) OpenInBrowser() error {
+	return item.OpenInBrowserWith(startCommand)
+}
+
+// OpenInBrowserWith is OpenInBrowser using run to launch the platform's
+// browser command.
+func (item *Item) OpenInBrowserWith(run CommandRunner) error {
+	url := item.GetRepositoryURL()
+	if url == "" {
+		return ErrEmptyRepositoryURL
+	}
+	name, args, err := browserCommand(runtime.GOOS, url)
+	if err != nil {
+		return err
+	}
+	return run(name, args...)
+}
@@ -0,0 +1,31 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	const repoURL = "https://github.com/ryo-ma/lazyhub"
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{"xdg-open", repoURL}},
+		{"darwin", []string{"open", repoURL}},
+		{"windows", []string{"rundll32", "url.dll,FileProtocolHandler", repoURL}},
+	}
+	for _, test := range tests {
+		name, args, err := browserCommand(test.goos, repoURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := append([]string{name}, args...); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: command = %q, want %q", test.goos, got, test.want)
+		}
+	}
+	if _, _, err := browserCommand("plan9", repoURL); err == nil || !strings.Contains(err.Error(), "plan9") {
+		t.Errorf("plan9: err = %v, want an unsupported platform error", err)
+	}
+}
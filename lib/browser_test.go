@@ -0,0 +1,40 @@
+package lib_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestOpenInBrowserWith(t *testing.T) {
+	var commands [][]string
+	run := func(name string, args ...string) error {
+		commands = append(commands, append([]string{name}, args...))
+		return nil
+	}
+	item := lib.Item{Name: "ryo-ma / lazyhub", URL: "https://github.com/ryo-ma/lazyhub", DataSource: lib.SourceTrending}
+
+	err := item.OpenInBrowserWith(run)
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows", "freebsd", "openbsd", "netbsd":
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		if err == nil {
+			t.Errorf("want an error on unsupported %s", runtime.GOOS)
+		}
+		return
+	}
+	if len(commands) != 1 || commands[0][len(commands[0])-1] != "https://github.com/ryo-ma/lazyhub" {
+		t.Errorf("commands = %q, want one command opening the repository URL", commands)
+	}
+
+	if err := (&lib.Item{}).OpenInBrowserWith(run); err != lib.ErrEmptyRepositoryURL {
+		t.Errorf("err = %v, want ErrEmptyRepositoryURL", err)
+	}
+	if len(commands) != 1 {
+		t.Errorf("ran %d commands, want none for an item without a URL", len(commands)-1)
+	}
+}
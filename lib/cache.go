@@ -0,0 +1,97 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type FileCache struct {
+	Dir string
+	TTL time.Duration
+}
+
+type cacheEntry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+func NewFileCache(dir string, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileCache{Dir: dir, TTL: ttl}, nil
+}
+
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{req.Method, req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (cache *FileCache) path(req *http.Request) string {
+	return filepath.Join(cache.Dir, cacheKey(req)+".json")
+}
+
+func (cache *FileCache) get(req *http.Request) (*http.Response, []byte, bool) {
+	path := cache.path(req)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	if cache.TTL > 0 && time.Since(info.ModTime()) > cache.TTL {
+		return nil, nil, false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil, false
+	}
+	resp := &http.Response{
+		StatusCode: entry.StatusCode,
+		Header:     entry.Header,
+		Request:    req,
+	}
+	return resp, entry.Body, true
+}
+
+func (cache *FileCache) set(req *http.Request, resp *http.Response, body []byte) error {
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return nil
+	}
+	data, err := json.Marshal(cacheEntry{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(cache.Dir, "entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), cache.path(req))
+}
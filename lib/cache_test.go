@@ -0,0 +1,112 @@
+package lib_test
+
+import (
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestFileCacheHitSkipsRequest(t *testing.T) {
+	var searches int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&searches, 1)
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	cache, err := lib.NewFileCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = cache
+
+	first, err := client.SearchRepository("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.SearchRepository("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searches != 1 {
+		t.Errorf("made %d requests, want 1 with a cache hit", searches)
+	}
+	if !reflect.DeepEqual(names(first.Items), names(second.Items)) {
+		t.Errorf("cached items = %v, want %v", names(second.Items), names(first.Items))
+	}
+
+	if _, err := client.SearchRepository("rust"); err != nil {
+		t.Fatal(err)
+	}
+	if searches != 2 {
+		t.Errorf("made %d requests, want a different query to miss the cache", searches)
+	}
+}
+
+func TestFileCacheExpires(t *testing.T) {
+	var searches int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&searches, 1)
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	cache, err := lib.NewFileCache(t.TempDir(), time.Nanosecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SearchRepository("go"); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if searches != 2 {
+		t.Errorf("made %d requests, want an expired entry to be refetched", searches)
+	}
+}
+
+func TestFileCacheSkipsUndecodableResponses(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte("<html>waking up</html>"))
+				return
+			}
+			jsonBody(lazyhubtest.TrendingResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	cache, err := lib.NewFileCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Cache = cache
+
+	if _, err := client.GetTrendingRepository("", ""); err == nil {
+		t.Fatal("want an error for an HTML response")
+	}
+	for i := 0; i < 2; i++ {
+		result, err := client.GetTrendingRepository("", "")
+		if err != nil {
+			t.Fatalf("call %d: %v", i+2, err)
+		}
+		if len(result.Items) != 1 {
+			t.Errorf("call %d: got %d items, want 1", i+2, len(result.Items))
+		}
+	}
+	if requests != 2 {
+		t.Errorf("made %d requests, want the HTML page skipped and the JSON response cached", requests)
+	}
+}
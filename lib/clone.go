@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type CommandRunner func(name string, args ...string) error
+
+func runCommand(name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s is not installed: %w", name, err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, message)
+		}
+		return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func (client *Client) runner() CommandRunner {
+	if client.RunCommand != nil {
+		return client.RunCommand
+	}
+	return runCommand
+}
+
+func (client *Client) CloneRepository(item Item, destDir string) error {
+	if destDir == "" {
+		return errors.New("clone destination must not be empty")
+	}
+	if _, err := os.Stat(destDir); err == nil {
+		return fmt.Errorf("clone destination %s already exists", destDir)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return client.runner()("git", "clone", "--", item.GetCloneURL(), destDir)
+}
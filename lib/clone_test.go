@@ -0,0 +1,43 @@
+package lib_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestCloneRepository(t *testing.T) {
+	var commands [][]string
+	client := &lib.Client{RunCommand: func(name string, args ...string) error {
+		commands = append(commands, append([]string{name}, args...))
+		return nil
+	}}
+	dest := filepath.Join(t.TempDir(), "lazyhub")
+
+	if err := client.CloneRepository(lib.Item{Name: "ryo-ma / lazyhub", URL: "https://github.com/ryo-ma/lazyhub"}, dest); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"git", "clone", "--", "https://github.com/ryo-ma/lazyhub.git", dest}}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+
+	if err := client.CloneRepository(lib.Item{FullName: "ryo-ma/lazyhub"}, t.TempDir()); err == nil {
+		t.Error("cloning into an existing directory: want an error")
+	}
+	if err := client.CloneRepository(lib.Item{FullName: "ryo-ma/lazyhub"}, ""); err == nil {
+		t.Error("cloning into an empty destination: want an error")
+	}
+	if len(commands) != 1 {
+		t.Errorf("ran %d commands, want the rejected clones to run none", len(commands))
+	}
+
+	failure := errors.New("exit status 128")
+	client.RunCommand = func(name string, args ...string) error { return failure }
+	if err := client.CloneRepository(lib.Item{FullName: "ryo-ma/lazyhub"}, dest); err != failure {
+		t.Errorf("err = %v, want the runner's error", err)
+	}
+}
@@ -0,0 +1,63 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type DiscoverOptions struct {
+	Query    string
+	Language string
+	Since    string
+	Search   SearchOptions
+}
+
+func (client *Client) Discover(opts DiscoverOptions) (*Result, error) {
+	return client.DiscoverContext(context.Background(), opts)
+}
+
+// DiscoverContext blends trending repositories with search results. If one
+// source fails the other's items are still returned alongside a MultiError.
+func (client *Client) DiscoverContext(ctx context.Context, opts DiscoverOptions) (*Result, error) {
+	searchOpts := opts.Search
+	if searchOpts.Language == "" {
+		searchOpts.Language = opts.Language
+	}
+	query := searchOpts.QueryAt(opts.Query, client.now())
+
+	var wg sync.WaitGroup
+	var trending, search *Result
+	var trendingErr, searchErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		trending, trendingErr = client.GetTrendingRepositoryContext(ctx, opts.Language, opts.Since)
+	}()
+	if query != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			search, searchErr = client.SearchRepositoryWithOptionsContext(ctx, opts.Query, searchOpts)
+		}()
+	}
+	wg.Wait()
+
+	result := &Result{}
+	var errs MultiError
+	if trendingErr != nil {
+		errs = append(errs, fmt.Errorf("trending: %w", trendingErr))
+	} else {
+		result.Items = append(result.Items, trending.Items...)
+	}
+	if searchErr != nil {
+		errs = append(errs, fmt.Errorf("search: %w", searchErr))
+	} else if search != nil {
+		result.Items = append(result.Items, search.Items...)
+	}
+	result.Dedupe()
+	if len(errs) != 0 {
+		return result, errs
+	}
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package lib_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestDiscover(t *testing.T) {
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+
+	result, err := client.Discover(lib.DiscoverOptions{Query: "tui", Language: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("items = %v, want %v", names(result.Items), want)
+	}
+	if result.Items[0].DataSource != lib.SourceTrending {
+		t.Errorf("items[0].DataSource = %q, want the trending copy first", result.Items[0].DataSource)
+	}
+
+	server.Handle("/repo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Application Error", http.StatusServiceUnavailable)
+	}))
+	result, err = client.Discover(lib.DiscoverOptions{Query: "tui"})
+	errs, ok := err.(lib.MultiError)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("err = %#v, want a MultiError with the trending error", err)
+	}
+	var apiError *lib.APIError
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("errors.As(%v) = %v, want the trending 503", err, apiError)
+	}
+	if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("partial items = %v, want the search items %v", names(result.Items), want)
+	}
+}
@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const acceptEncoding = "gzip, deflate"
+
+// readBody reads the response body, decompressing it when the server
+// honored our Accept-Encoding. Setting Accept-Encoding ourselves disables
+// the transport's transparent gzip handling, so it has to happen here.
+// At most limit bytes are read after decompression.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	case "deflate":
+		// "deflate" is meant to be zlib-wrapped, but some servers send raw
+		// deflate streams, so sniff for the zlib header first.
+		buffered := bufio.NewReader(resp.Body)
+		header, err := buffered.Peek(2)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zlibReader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return nil, err
+			}
+			defer zlibReader.Close()
+			reader = zlibReader
+		} else {
+			flateReader := flate.NewReader(buffered)
+			defer flateReader.Close()
+			reader = flateReader
+		}
+	default:
+		return readLimited(reader, limit)
+	}
+	body, err := readLimited(reader, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	return body, nil
+}
+
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
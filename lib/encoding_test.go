@@ -0,0 +1,86 @@
+package lib_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func compressedHandler(encoding string, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		var writer io.WriteCloser
+		contentEncoding := encoding
+		switch encoding {
+		case "gzip":
+			writer = gzip.NewWriter(&buf)
+		case "zlib":
+			writer = zlib.NewWriter(&buf)
+			contentEncoding = "deflate"
+		case "deflate":
+			writer, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		}
+		writer.Write([]byte(body))
+		writer.Close()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", contentEncoding)
+		w.Write(buf.Bytes())
+	}
+}
+
+func TestCompressedResponses(t *testing.T) {
+	for _, encoding := range []string{"gzip", "zlib", "deflate"} {
+		var acceptEncoding string
+		handler := compressedHandler(encoding, lazyhubtest.SearchResponse)
+		client, server := newTestClient(t, map[string]http.Handler{
+			"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				handler(w, r)
+			}),
+		})
+		result, err := client.SearchRepository("go")
+		server.Close()
+		if err != nil {
+			t.Errorf("%s: %v", encoding, err)
+			continue
+		}
+		if acceptEncoding != "gzip, deflate" {
+			t.Errorf("%s: Accept-Encoding = %q, want gzip, deflate", encoding, acceptEncoding)
+		}
+		if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui"}; !reflect.DeepEqual(names(result.Items), want) {
+			t.Errorf("%s: items = %v, want %v", encoding, names(result.Items), want)
+		}
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/readme": compressedHandler("gzip", `{"content":"`+strings.Repeat("a", 1000)+`"}`),
+	})
+	defer server.Close()
+	client.MaxResponseBytes = 100
+
+	_, searchErr := client.SearchRepository("go")
+	_, readmeErr := client.GetReadme(lib.Item{FullName: "ryo-ma/lazyhub"})
+	_, trendingErr := client.GetTrendingRepository("go", "")
+	for name, err := range map[string]error{"search": searchErr, "gzip readme": readmeErr, "trending": trendingErr} {
+		if !errors.Is(err, lib.ErrResponseTooLarge) {
+			t.Errorf("%s: err = %v, want ErrResponseTooLarge", name, err)
+		}
+	}
+
+	client.MaxResponseBytes = 0
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Errorf("default limit: %v", err)
+	}
+}
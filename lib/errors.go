@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("github api: not found")
+
+// errEmptyResponse reports a 2xx response whose body decoded to JSON null.
+var errEmptyResponse = errors.New("github api: empty response")
+
+var ErrResponseTooLarge = errors.New("response body too large")
+
+var ErrSecondaryRateLimit = errors.New("github api: secondary rate limit exceeded")
+
+type APIError struct {
+	StatusCode       int    `json:"-"`
+	Message          string `json:"message"`
+	DocumentationURL string `json:"documentation_url"`
+	// RetryAfter is the wait requested by a Retry-After header, or zero.
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("github api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("github api: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.IsNotFound()
+	case ErrSecondaryRateLimit:
+		return e.IsSecondaryRateLimit()
+	default:
+		return false
+	}
+}
+
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsSecondaryRateLimit reports GitHub's secondary (abuse) rate limit, which
+// arrives as a 403 or 429 without the usual rate limit headers.
+func (e *APIError) IsSecondaryRateLimit() bool {
+	if e.StatusCode != http.StatusForbidden && e.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	message := strings.ToLower(e.Message)
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500 || e.IsSecondaryRateLimit()
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiError := &APIError{}
+	// Not every backend returns GitHub's error shape, so a body that fails
+	// to parse still yields an APIError carrying the status code.
+	_ = json.Unmarshal(body, apiError)
+	apiError.StatusCode = resp.StatusCode
+	apiError.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return apiError
+}
+
+type MultiError []error
+
+func (e MultiError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap lets errors.Is and errors.As match any of the collected errors.
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+const decodeErrorSnippetLength = 200
+
+type DecodeError struct {
+	StatusCode  int
+	ContentType string
+	Snippet     string
+	Err         error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decode response (status %d, content-type %q): %v: %q", e.StatusCode, e.ContentType, e.Err, e.Snippet)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func decodeJSON(resp *http.Response, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		snippet := body
+		if len(snippet) > decodeErrorSnippetLength {
+			snippet = snippet[:decodeErrorSnippetLength]
+		}
+		return &DecodeError{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Snippet:     string(snippet),
+			Err:         err,
+		}
+	}
+	return nil
+}
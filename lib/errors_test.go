@@ -0,0 +1,90 @@
+package lib_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestNotFoundIsAPIError(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/owner/missing": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found","documentation_url":"https://docs.github.com/rest"}`))
+		}),
+	})
+	defer server.Close()
+
+	item, err := client.GetRepository("owner", "missing")
+	if item != nil {
+		t.Errorf("item = %+v, want nil", item)
+	}
+	var apiError *lib.APIError
+	if !errors.As(err, &apiError) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiError.StatusCode != http.StatusNotFound || apiError.Message != "Not Found" || apiError.DocumentationURL != "https://docs.github.com/rest" {
+		t.Errorf("APIError = %+v", apiError)
+	}
+	if apiError.Temporary() {
+		t.Error("a 404 should not be temporary")
+	}
+}
+
+func TestDecodeErrorIncludesSnippet(t *testing.T) {
+	malformed := `{"total_count": 1, "items": [oops` + strings.Repeat(" padding", 100)
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories":  jsonBody(malformed),
+		"/repos/ryo-ma/lazyhub": jsonBody(malformed),
+	})
+	defer server.Close()
+
+	_, searchErr := client.SearchRepository("go")
+	_, repoErr := client.GetRepository("ryo-ma", "lazyhub")
+	for name, err := range map[string]error{"search": searchErr, "repository": repoErr} {
+		var decodeError *lib.DecodeError
+		if !errors.As(err, &decodeError) {
+			t.Errorf("%s: err = %v, want *DecodeError", name, err)
+			continue
+		}
+		if decodeError.StatusCode != http.StatusOK || decodeError.ContentType != "application/json" {
+			t.Errorf("%s: DecodeError = %+v", name, decodeError)
+		}
+		if !strings.HasPrefix(decodeError.Snippet, `{"total_count": 1, "items": [oops`) || len(decodeError.Snippet) >= len(malformed) {
+			t.Errorf("%s: Snippet = %q, want a truncated prefix of the body", name, decodeError.Snippet)
+		}
+		if !strings.Contains(err.Error(), "[oops") {
+			t.Errorf("%s: error message %q does not include the snippet", name, err)
+		}
+	}
+}
+
+func TestGetReadmeNotFound(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/owner/empty/readme": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}),
+		"/repos/owner/broken/readme": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "oops", http.StatusInternalServerError)
+		}),
+	})
+	defer server.Close()
+
+	readme, err := client.GetReadme(lib.Item{FullName: "owner/empty"})
+	if readme != nil || !lib.IsNotFound(err) || !errors.Is(err, lib.ErrNotFound) {
+		t.Errorf("GetReadme = %v, %v; want ErrNotFound", readme, err)
+	}
+	var decodeError *lib.DecodeError
+	if errors.As(err, &decodeError) {
+		t.Errorf("err = %v, want an API error rather than a decode error", err)
+	}
+	if _, err := client.GetReadme(lib.Item{FullName: "owner/broken"}); err == nil || lib.IsNotFound(err) {
+		t.Errorf("500: err = %v, want an error other than not found", err)
+	}
+}
@@ -2,22 +2,90 @@ package lib
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"math"
+	"mime"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 )
 
 type Client struct {
 	OfficialURL           *url.URL
 	TrendingRepositoryURL *url.URL
+	TrendingDeveloperURL  *url.URL
 	HTTPClient            *http.Client
+	// Token is a GitHub personal access token sent as a Bearer token on
+	// every request, including trending requests (which ignore it).
+	Token     string
+	UserAgent string
+	// Accept is the default media type; methods needing a different
+	// representation (HTML, star timestamps) override it per request.
+	Accept string
+	// Previews lists API preview names (such as "mercy") whose media types
+	// are added to the default Accept header. See WithPreview.
+	Previews []string
+	Retry    RetryPolicy
+	Cache    *FileCache
+	// TrackETags makes GetRepository send If-None-Match and reuse the
+	// previously fetched Item when GitHub answers 304 Not Modified.
+	TrackETags bool
+	// TrendingFallback makes GetTrendingRepository fall back to a search
+	// when the trending backend is unavailable. See Result.Fallback. If the
+	// search fails too, both errors are returned in a MultiError.
+	TrendingFallback bool
+	RunCommand       CommandRunner
+	// Logger, when set, receives one entry per HTTP request. Request
+	// headers are never logged, so the token stays out of the logs.
+	Logger Logger
+	// RequestInterceptors run in order on every outgoing request, after
+	// the default AcceptHeader interceptor and once the Authorization and
+	// User-Agent headers have been set, so they can override any of them.
+	RequestInterceptors []RequestInterceptor
+	// Now returns the current time for date qualifiers; it defaults to
+	// time.Now and exists so tests can pin the clock.
+	Now func() time.Time
+	// MaxResponseBytes caps how much of a (decompressed) response body is
+	// read; larger responses fail with ErrResponseTooLarge. Zero means
+	// DefaultMaxResponseBytes.
+	MaxResponseBytes int64
+	// TrendingProvider, when set, replaces the default trending backend at
+	// TrendingRepositoryURL for trending repositories.
+	TrendingProvider TrendingProvider
+
+	mu            sync.Mutex
+	lastRateLimit *RateLimit
+	etags         map[string]etagEntry
+}
+
+type Logger func(msg string, keyvals ...interface{})
+
+// TrendingProvider is an alternative source of trending repositories, such
+// as a self-hosted scraper. Items without a DataSource are marked as
+// SourceTrending.
+type TrendingProvider interface {
+	TrendingRepositories(ctx context.Context, language string, since string) ([]Item, error)
+}
+
+// TrendingHealthChecker is optionally implemented by a TrendingProvider to
+// back Client.TrendingHealthy.
+type TrendingHealthChecker interface {
+	TrendingHealthy(ctx context.Context) (bool, error)
+}
+
+type etagEntry struct {
+	etag string
+	item Item
 }
 
 type Item struct {
@@ -32,13 +100,36 @@ type Item struct {
 	StargazersCount int      `json:"stargazers_count,stars"`
 	Stars           string   `json:"stars"`
 	Watchers        int      `json:"watchers"`
+	OpenIssuesCount int      `json:"open_issues_count"`
 	Topics          []string `json:"topics"`
 	Language        string   `json:"language"`
 	Lang            string   `json:"lang"`
 	DefaultBranch   string   `json:"default_branch"`
 	CreatedAt       string   `json:"created_at"`
 	UpdatedAt       string   `json:"updated_at"`
-	DataSource      string
+	DataSource      DataSource
+}
+
+type DataSource string
+
+const (
+	SourceOfficial DataSource = "OfficialAPI"
+	SourceTrending DataSource = "TrendingAPI"
+	SourceGraphQL  DataSource = "GraphQLAPI"
+)
+
+type Developer struct {
+	Username    string `json:"user"`
+	Name        string `json:"full_name"`
+	URL         string `json:"user_link"`
+	Avatar      string `json:"developer_avatar"`
+	Repo        string `json:"repo"`
+	RepoURL     string `json:"repo_link"`
+	Description string `json:"desc"`
+}
+
+type DeveloperResult struct {
+	Items []Developer `json:"items"`
 }
 
 type Readme struct {
@@ -47,32 +138,134 @@ type Readme struct {
 	HTMLURL     string `json:"html_url"`
 	DownloadURL string `json:"download_url"`
 	Content     string `json:"content"`
+	Encoding    string `json:"encoding"`
+}
+
+type ReadmeOptions struct {
+	Ref string
+	// AbsoluteURLs rewrites relative image and link URLs in the README to
+	// absolute raw.githubusercontent.com URLs.
+	AbsoluteURLs bool
 }
 
 type Result struct {
-	Items []Item `json:"items"`
+	TotalCount        int    `json:"total_count"`
+	IncompleteResults bool   `json:"incomplete_results"`
+	Items             []Item `json:"items"`
+	Page              int    `json:"-"`
+	PerPage           int    `json:"-"`
+	Links             Links  `json:"-"`
+	// Fallback reports that trending was unavailable and the items come
+	// from a search approximating it instead.
+	Fallback bool `json:"-"`
+}
+
+const defaultPerPage = 30
+
+func (readme *Readme) DecodedContent() (string, error) {
+	switch readme.Encoding {
+	case "", "none":
+		return readme.Content, nil
+	case "base64":
+		content := strings.NewReplacer("\n", "", "\r", "").Replace(readme.Content)
+		b, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("unsupported readme encoding %q", readme.Encoding)
+	}
 }
 
 func (item *Item) GetRepositoryName() string {
 	name := item.FullName
-	if name == "" {
+	if name == "" && item.URL != "" {
 		url, err := url.Parse(item.URL)
-		if err != nil {
-			return ""
+		if err == nil {
+			name = strings.Trim(url.Path, "/")
 		}
-		name = url.Path[1:]
 	}
-	return name
+	if name == "" && strings.Contains(item.Name, "/") {
+		name = item.Name
+	}
+	return normalizeRepositoryName(name)
+}
+
+func (item *Item) GetOwner() string {
+	owner, _ := splitRepositoryName(item.GetRepositoryName())
+	return owner
+}
+
+func (item *Item) GetRepo() string {
+	_, repo := splitRepositoryName(item.GetRepositoryName())
+	return repo
+}
+
+func splitRepositoryName(name string) (string, string) {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+func normalizeRepositoryName(name string) string {
+	parts := strings.Split(strings.TrimSpace(name), "/")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return strings.Join(parts, "/")
 }
 
 func (item *Item) GetStars() int {
-	stars, _ := strconv.Atoi(strings.Replace(item.Stars, ",", "", -1))
+	if item.DataSource != SourceTrending && item.StargazersCount != 0 {
+		return item.StargazersCount
+	}
+	stars := parseStars(item.Stars)
 	if stars == 0 {
 		return item.StargazersCount
 	}
 	return stars
 }
 
+// parseStars parses counts such as "1,234", "987", "12k" and "1.2M".
+func parseStars(s string) int {
+	s = strings.Replace(strings.TrimSpace(s), ",", "", -1)
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		multiplier = 1e3
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "M"):
+		multiplier = 1e6
+	}
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return int(math.Round(value * multiplier))
+}
+
+// FormattedStars renders GetStars compactly, e.g. "987", "1.2k" or "3.4M".
+func (item *Item) FormattedStars() string {
+	stars := item.GetStars()
+	switch {
+	case stars < 1000:
+		return strconv.Itoa(stars)
+	case stars < 999950:
+		return formatStarUnit(float64(stars)/1e3) + "k"
+	default:
+		return formatStarUnit(float64(stars)/1e6) + "M"
+	}
+}
+
+func formatStarUnit(value float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(value, 'f', 1, 64), ".0")
+}
+
 func (item *Item) GetRepositoryURL() string {
 	url := item.HTMLURL
 	if url == "" {
@@ -95,6 +288,9 @@ func (item *Item) GetLanguage() string {
 	return language
 }
 func (item *Item) GetCloneURL() string {
+	if item.CloneURL != "" {
+		return item.CloneURL
+	}
 	url := item.GetRepositoryURL()
 	if !strings.HasSuffix(url, ".git") {
 		return url + ".git"
@@ -106,7 +302,7 @@ func (item *Item) String() string {
 	const officialTemplateText = `
 	Name       : {{.GetRepositoryName}}
 	URL        : {{.GetRepositoryURL}}
-	Star       : ⭐️ {{.StargazersCount}}
+	Star       : ⭐️ {{.GetStars}}
 	Clone URL  : {{.GetCloneURL}}
 	Description: {{.Description}}
 	Watchers   : {{.Watchers}}
@@ -118,13 +314,13 @@ func (item *Item) String() string {
 	const trendingTemplateText = `
 	Name       : {{.GetRepositoryName}}
 	URL        : {{.GetRepositoryURL}}
-	Star       : ⭐️ {{.Stars}}
+	Star       : ⭐️ {{.GetStars}}
 	Clone URL  : {{.GetCloneURL}}
 	Description: {{.GetDescription}}
 	Language   : {{.GetLanguage}}
 	`
 	templateText := trendingTemplateText
-	if item.DataSource == "OfficialAPI" {
+	if item.DataSource == SourceOfficial || item.DataSource == SourceGraphQL {
 		templateText = officialTemplateText
 	}
 	template, err := template.New("Repository").Parse(templateText)
@@ -138,113 +334,754 @@ func (item *Item) String() string {
 	return doc.String()
 }
 
+type DrawOptions struct {
+	NoColor   bool
+	Color     string
+	StarWidth int
+}
+
+const (
+	defaultDrawColor     = "32"
+	defaultDrawStarWidth = 10
+)
+
+func NewDrawOptions(writer io.Writer) DrawOptions {
+	return DrawOptions{NoColor: os.Getenv("NO_COLOR") != "" || !isTerminal(writer)}
+}
+
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 func (result *Result) Draw(writer io.Writer) error {
+	return result.DrawWithOptions(writer, DrawOptions{})
+}
+
+func (result *Result) DrawWithOptions(writer io.Writer, opts DrawOptions) error {
+	color := opts.Color
+	if color == "" {
+		color = defaultDrawColor
+	}
+	width := opts.StarWidth
+	if width <= 0 {
+		width = defaultDrawStarWidth
+	}
 	for _, item := range result.Items {
 		starText := " ⭐️ " + strconv.Itoa(item.GetStars())
-		fmt.Fprintf(writer, "%-10.10s\033[32m%s\033[0m\n", starText, item.GetRepositoryName())
+		if opts.NoColor {
+			fmt.Fprintf(writer, "%-*.*s%s\n", width, width, starText, item.GetRepositoryName())
+			continue
+		}
+		fmt.Fprintf(writer, "%-*.*s\033[%sm%s\033[0m\n", width, width, starText, color, item.GetRepositoryName())
 	}
 	return nil
 }
 
-func NewClient() (*Client, error) {
-	officialURL, err := url.Parse("https://api.github.com")
+// DrawPaged is Draw followed by a "Page X of Y (N results)" footer computed
+// from TotalCount.
+func (result *Result) DrawPaged(writer io.Writer, page int, perPage int) error {
+	return result.DrawPagedWithOptions(writer, page, perPage, DrawOptions{})
+}
+
+func (result *Result) DrawPagedWithOptions(writer io.Writer, page int, perPage int, opts DrawOptions) error {
+	if err := result.DrawWithOptions(writer, opts); err != nil {
+		return err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	pages := (result.TotalCount + perPage - 1) / perPage
+	if pages < 1 {
+		pages = 1
+	}
+	_, err := fmt.Fprintf(writer, "Page %d of %d (%d results)\n", page, pages, result.TotalCount)
+	return err
+}
+
+func (result *Result) HasNextPage() bool {
+	if !result.Links.empty() {
+		return result.Links.Next != ""
+	}
+	page := result.Page
+	if page == 0 {
+		page = 1
+	}
+	perPage := result.PerPage
+	if perPage == 0 {
+		perPage = defaultPerPage
+	}
+	return page*perPage < result.TotalCount
+}
+
+const (
+	defaultOfficialURL           = "https://api.github.com"
+	defaultTrendingRepositoryURL = "https://trendings.herokuapp.com/repo"
+	defaultTrendingDeveloperURL  = "https://trendings.herokuapp.com/dev"
+	DefaultTimeout               = 30 * time.Second
+	DefaultUserAgent             = "LazyHub/" + version
+	defaultMediaType             = "application/vnd.github+json"
+	DefaultMaxResponseBytes      = 10 << 20
+)
+
+func NewClient(opts ...Option) (*Client, error) {
+	officialURL, err := parseBaseURL(defaultOfficialURL)
 	if err != nil {
 		return nil, err
 	}
-	trendingRepositoryURL, err := url.Parse("https://trendings.herokuapp.com/repo")
+	trendingRepositoryURL, err := url.Parse(defaultTrendingRepositoryURL)
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
+	trendingDeveloperURL, err := url.Parse(defaultTrendingDeveloperURL)
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{
 		OfficialURL:           officialURL,
 		TrendingRepositoryURL: trendingRepositoryURL,
-		HTTPClient:            http.DefaultClient,
-	}, nil
+		TrendingDeveloperURL:  trendingDeveloperURL,
+		HTTPClient:            &http.Client{Timeout: DefaultTimeout, Transport: newTransport()},
+		Accept:                defaultMediaType,
+		Now:                   time.Now,
+		MaxResponseBytes:      DefaultMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		if err := opt(client); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
 }
 
-func (client *Client) SearchRepository(query string) (*Result, error) {
-	url := *client.OfficialURL
-	url.Path = path.Join(url.Path, "search", "repositories")
-	req, err := http.NewRequest("GET", url.String()+"?q="+query, nil)
+func newTransport() http.RoundTripper {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return http.DefaultTransport
+	}
+	transport = transport.Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	return transport
+}
+
+func NewClientWithBaseURL(baseURL string) (*Client, error) {
+	return NewClient(WithBaseURL(baseURL))
+}
+
+func (client *Client) now() time.Time {
+	if client.Now == nil {
+		return time.Now()
+	}
+	return client.Now()
+}
+
+func (client *Client) maxResponseBytes() int64 {
+	if client.MaxResponseBytes <= 0 {
+		return DefaultMaxResponseBytes
+	}
+	return client.MaxResponseBytes
+}
+
+// SetTimeout sets the HTTP client timeout, which bounds each attempt. A
+// deadline on the context passed to a ...Context method bounds the whole
+// call including retries; whichever expires first wins, and an expired
+// context is reported as the context's error. The timeout is set on a copy,
+// so an *http.Client shared with other code is not reconfigured.
+func (client *Client) SetTimeout(timeout time.Duration) {
+	httpClient := &http.Client{}
+	if client.HTTPClient != nil && client.HTTPClient != http.DefaultClient {
+		copied := *client.HTTPClient
+		httpClient = &copied
+	}
+	httpClient.Timeout = timeout
+	client.HTTPClient = httpClient
+}
+
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	u, err := url.Parse(baseURL)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q: must include a scheme and host", baseURL)
+	}
+	return u, nil
+}
+
+func (client *Client) newRequest(ctx context.Context, method string, url string) (*http.Request, error) {
+	return client.newRequestWithBody(ctx, method, url, "", nil)
+}
+
+func (client *Client) newRequestAccept(ctx context.Context, url string, accept string) (*http.Request, error) {
+	return client.newRequestWithBody(ctx, "GET", url, accept, nil)
+}
+
+// newRequestWithBody builds a request with the client's default headers.
+// An empty accept falls back to Client.Accept plus any Previews; either way
+// the Accept header is applied through the interceptor chain.
+func (client *Client) newRequestWithBody(ctx context.Context, method string, url string, accept string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if client.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+	userAgent := client.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	for _, intercept := range client.interceptors(accept) {
+		intercept(req)
+	}
+	return req, nil
+}
+
+// interceptors returns the default AcceptHeader interceptor, for accept or
+// the client's default media type, followed by Client.RequestInterceptors.
+func (client *Client) interceptors(accept string) []RequestInterceptor {
+	if accept == "" {
+		accept = client.defaultAccept()
+	}
+	interceptors := make([]RequestInterceptor, 0, len(client.RequestInterceptors)+1)
+	if accept != "" {
+		interceptors = append(interceptors, AcceptHeader(accept))
 	}
-	req.Header.Add("Accept", "application/vnd.github.mercy-preview+json")
+	return append(interceptors, client.RequestInterceptors...)
+}
+
+func (client *Client) defaultAccept() string {
+	mediaTypes := make([]string, 0, len(client.Previews)+1)
+	for _, preview := range client.Previews {
+		mediaTypes = append(mediaTypes, "application/vnd.github."+preview+"-preview+json")
+	}
+	if client.Accept != "" {
+		mediaTypes = append(mediaTypes, client.Accept)
+	}
+	return strings.Join(mediaTypes, ", ")
+}
+
+type RequestInterceptor func(req *http.Request)
+
+func AcceptHeader(mediaType string) RequestInterceptor {
+	return func(req *http.Request) {
+		req.Header.Set("Accept", mediaType)
+	}
+}
+
+// responseDecoder parses a successful response body.
+type responseDecoder func(resp *http.Response, body []byte) error
+
+func jsonDecoder(v interface{}) responseDecoder {
+	return func(resp *http.Response, body []byte) error {
+		return decodeJSON(resp, body, v)
+	}
+}
+
+func trendingDecoder(v interface{}) responseDecoder {
+	return func(resp *http.Response, body []byte) error {
+		return decodeTrending(resp, body, v)
+	}
+}
+
+// do sends req and hands the response to decode, which may be nil for raw
+// bodies. A GET response is only written to the Cache after decode accepts
+// it, so an error page served with a 200 is never replayed from the cache.
+func (client *Client) do(req *http.Request, decode responseDecoder) (*http.Response, []byte, error) {
+	cache := client.Cache
+	if req.Method != "GET" {
+		cache = nil
+	}
+	if cache != nil {
+		if resp, body, ok := cache.get(req); ok {
+			return resp, body, decodeResponse(decode, resp, body)
+		}
+	}
+	resp, body, err := client.doWithRetry(req)
+	if err != nil {
+		return resp, body, err
+	}
+	if err := decodeResponse(decode, resp, body); err != nil {
+		return resp, body, err
+	}
+	if cache != nil && resp.StatusCode == http.StatusOK {
+		// A failed cache write only costs a future request.
+		_ = cache.set(req, resp, body)
+	}
+	return resp, body, nil
+}
+
+func decodeResponse(decode responseDecoder, resp *http.Response, body []byte) error {
+	if decode == nil {
+		return nil
+	}
+	return decode(resp, body)
+}
+
+func (client *Client) doWithRetry(req *http.Request) (*http.Response, []byte, error) {
+	attempts := client.Retry.attempts()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+			req.Body = body
+		}
+		resp, body, err := client.doOnce(req)
+		if attempt >= attempts || !shouldRetry(req.Context(), resp, err) {
+			return resp, body, err
+		}
+		if err := sleepContext(req.Context(), client.Retry.delay(attempt, resp)); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (client *Client) doOnce(req *http.Request) (*http.Response, []byte, error) {
+	start := time.Now()
 	resp, err := client.HTTPClient.Do(req)
+	client.logRequest(req, resp, err, time.Since(start))
 	if err != nil {
-		panic(err)
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	client.recordRateLimit(resp.Header)
+	body, err := readBody(resp, client.maxResponseBytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, body, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, body, newAPIError(resp, body)
+	}
+	return resp, body, nil
+}
+
+func (client *Client) logRequest(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if client.Logger == nil {
+		return
+	}
+	keyvals := []interface{}{"method", req.Method, "url", req.URL.String(), "duration", duration}
+	if resp != nil {
+		keyvals = append(keyvals, "status", resp.StatusCode)
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+	}
+	client.Logger("http request", keyvals...)
+}
+
+func (client *Client) getJSON(ctx context.Context, url string, v interface{}) (*http.Response, error) {
+	req, err := client.newRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, err
+	}
+	resp, _, err := client.do(req, func(resp *http.Response, body []byte) error {
+		if resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		return decodeJSON(resp, body, v)
+	})
+	return resp, err
+}
+
+func (client *Client) apiURL(elem ...string) url.URL {
+	url := *client.OfficialURL
+	url.Path = path.Join(append([]string{url.Path}, elem...)...)
+	return url
+}
+
+func (client *Client) SearchRepository(query string) (*Result, error) {
+	return client.SearchRepositoryContext(context.Background(), query)
+}
+
+func (client *Client) SearchRepositoryContext(ctx context.Context, query string) (*Result, error) {
+	return client.SearchRepositoryWithOptionsContext(ctx, query, SearchOptions{})
+}
+
+func (client *Client) SearchRepositoryWithOptions(query string, opts SearchOptions) (*Result, error) {
+	return client.SearchRepositoryWithOptionsContext(context.Background(), query, opts)
+}
+
+func (client *Client) SearchRepositoryWithOptionsContext(ctx context.Context, query string, opts SearchOptions) (*Result, error) {
+	url := *client.OfficialURL
+	url.Path = path.Join(url.Path, "search", "repositories")
+	q := url.Query()
+	q.Set("q", opts.QueryAt(query, client.now()))
+	if opts.Page > 0 {
+		q.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+	if opts.Sort != "" {
+		q.Set("sort", opts.Sort)
+	}
+	switch opts.Order {
+	case "":
+	case "asc", "desc":
+		q.Set("order", opts.Order)
+	default:
+		return nil, fmt.Errorf("invalid search order %q: must be asc or desc", opts.Order)
+	}
+	url.RawQuery = q.Encode()
+	return client.searchRepositoryURL(ctx, url.String(), opts.Page, opts.PerPage)
+}
+
+func (client *Client) searchRepositoryURL(ctx context.Context, url string, page int, perPage int) (*Result, error) {
+	req, err := client.newRequest(ctx, "GET", url)
 	if err != nil {
 		return nil, err
 	}
 	var result *Result
-	if err = json.Unmarshal(body, &result); err != nil {
+	resp, _, err := client.do(req, jsonDecoder(&result))
+	if err != nil {
 		return nil, err
 	}
+	if result == nil {
+		return nil, errEmptyResponse
+	}
 	items := result.Items
 	for i := range items {
-		result.Items[i].DataSource = "OfficialAPI"
+		result.Items[i].DataSource = SourceOfficial
 	}
+	result.Page = page
+	result.PerPage = perPage
+	result.Links = ParseLinkHeader(resp.Header.Get("Link"))
 	return result, nil
 }
 
 func (client *Client) GetReadme(item Item) (*Readme, error) {
+	return client.GetReadmeContext(context.Background(), item)
+}
+
+func (client *Client) GetReadmeContext(ctx context.Context, item Item) (*Readme, error) {
+	return client.GetReadmeWithOptionsContext(ctx, item, ReadmeOptions{})
+}
+
+func (client *Client) GetReadmeWithOptions(item Item, opts ReadmeOptions) (*Readme, error) {
+	return client.GetReadmeWithOptionsContext(context.Background(), item, opts)
+}
+
+func (client *Client) GetReadmeWithOptionsContext(ctx context.Context, item Item, opts ReadmeOptions) (*Readme, error) {
 	url := *client.OfficialURL
-	url.Path = path.Join(url.Path, "repos", item.GetRepositoryName(), "readme")
-	req, err := http.NewRequest("GET", url.String(), nil)
+	url.Path = path.Join(url.Path, "repos", item.GetOwner(), item.GetRepo(), "readme")
+	if opts.Ref != "" {
+		q := url.Query()
+		q.Set("ref", opts.Ref)
+		url.RawQuery = q.Encode()
+	}
+	req, err := client.newRequest(ctx, "GET", url.String())
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	req.Header.Add("Accept", "application/vnd.github.mercy-preview+json")
-	resp, err := client.HTTPClient.Do(req)
+	var readme *Readme
+	if _, _, err := client.do(req, jsonDecoder(&readme)); err != nil {
+		return nil, err
+	}
+	if readme == nil {
+		return nil, errEmptyResponse
+	}
+	if opts.AbsoluteURLs {
+		ref := opts.Ref
+		if ref == "" {
+			ref = item.DefaultBranch
+		}
+		if err := readme.rewriteRelativeURLs(item.GetRepositoryName(), ref); err != nil {
+			return nil, err
+		}
+	}
+	return readme, nil
+}
+
+func (client *Client) GetRepository(owner string, name string) (*Item, error) {
+	return client.GetRepositoryContext(context.Background(), owner, name)
+}
+
+func (client *Client) GetRepositoryContext(ctx context.Context, owner string, name string) (*Item, error) {
+	url := *client.OfficialURL
+	url.Path = path.Join(url.Path, "repos", owner, name)
+	req, err := client.newRequest(ctx, "GET", url.String())
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	cached, hasCached := client.cachedETag(url.String())
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	var item *Item
+	resp, _, err := client.do(req, func(resp *http.Response, body []byte) error {
+		if resp.StatusCode == http.StatusNotModified {
+			return nil
+		}
+		return decodeJSON(resp, body, &item)
+	})
 	if err != nil {
 		return nil, err
 	}
-	var readme *Readme
-	if err = json.Unmarshal(body, &readme); err != nil {
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		item := cached.item
+		return &item, nil
+	}
+	if item == nil {
+		return nil, errEmptyResponse
+	}
+	item.DataSource = SourceOfficial
+	client.storeETag(url.String(), resp.Header.Get("ETag"), *item)
+	return item, nil
+}
+
+func (client *Client) cachedETag(url string) (etagEntry, bool) {
+	if !client.TrackETags {
+		return etagEntry{}, false
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	entry, ok := client.etags[url]
+	return entry, ok
+}
+
+func (client *Client) storeETag(url string, etag string, item Item) {
+	if !client.TrackETags || etag == "" {
+		return
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.etags == nil {
+		client.etags = make(map[string]etagEntry)
+	}
+	client.etags[url] = etagEntry{etag: etag, item: item}
+}
+
+func (client *Client) GetRepositoryTopics(owner string, name string) ([]string, error) {
+	return client.GetRepositoryTopicsContext(context.Background(), owner, name)
+}
+
+func (client *Client) GetRepositoryTopicsContext(ctx context.Context, owner string, name string) ([]string, error) {
+	url := *client.OfficialURL
+	url.Path = path.Join(url.Path, "repos", owner, name, "topics")
+	req, err := client.newRequest(ctx, "GET", url.String())
+	if err != nil {
 		return nil, err
 	}
-	return readme, nil
+	var topics struct {
+		Names []string `json:"names"`
+	}
+	if _, _, err := client.do(req, jsonDecoder(&topics)); err != nil {
+		return nil, err
+	}
+	return topics.Names, nil
 }
+
 func (client *Client) GetTrendingRepository(language string, since string) (*Result, error) {
-	q := client.TrendingRepositoryURL.Query()
-	if language != "" {
-		q.Set("lang", language)
+	return client.GetTrendingRepositoryContext(context.Background(), language, since)
+}
+
+func (client *Client) GetTrendingRepositoryContext(ctx context.Context, language string, since string) (*Result, error) {
+	return client.GetTrendingRepositoryWithOptionsContext(ctx, TrendingOptions{Language: language, Since: since})
+}
+
+type TrendingOptions struct {
+	Language string
+	Since    string
+	// Limit keeps only the first Limit items; zero means no limit. The
+	// trending backend has no server-side limit, so this trims locally.
+	Limit int
+}
+
+func (client *Client) GetTrendingRepositoryWithOptions(opts TrendingOptions) (*Result, error) {
+	return client.GetTrendingRepositoryWithOptionsContext(context.Background(), opts)
+}
+
+func (client *Client) GetTrendingRepositoryWithOptionsContext(ctx context.Context, opts TrendingOptions) (*Result, error) {
+	if err := validateSince(opts.Since); err != nil {
+		return nil, err
 	}
-	if since != "" {
-		q.Set("since", since)
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d: must not be negative", opts.Limit)
 	}
-	url := client.TrendingRepositoryURL
-	if len(q) != 0 {
-		url.RawQuery = q.Encode()
+	result, err := client.fetchTrendingRepository(ctx, opts.Language, opts.Since)
+	if err != nil && client.TrendingFallback && ctx.Err() == nil {
+		var searchErr error
+		result, searchErr = client.searchTrendingFallback(ctx, opts.Language, opts.Since)
+		if searchErr != nil {
+			return nil, MultiError{fmt.Errorf("trending: %w", err), fmt.Errorf("fallback search: %w", searchErr)}
+		}
+		err = nil
 	}
-	req, err := http.NewRequest("GET", url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Accept", "application/vnd.github.mercy-preview+json")
-	resp, err := client.HTTPClient.Do(req)
+	if opts.Limit > 0 && len(result.Items) > opts.Limit {
+		result.Items = result.Items[:opts.Limit]
+	}
+	return result, nil
+}
+
+// searchTrendingFallback approximates trending with a search for the most
+// starred repositories created within a window matching since.
+func (client *Client) searchTrendingFallback(ctx context.Context, language string, since string) (*Result, error) {
+	window := 7 * 24 * time.Hour
+	switch since {
+	case "weekly":
+		window = 30 * 24 * time.Hour
+	case "monthly":
+		window = 90 * 24 * time.Hour
+	}
+	result, err := client.SearchRepositoryWithOptionsContext(ctx, "", SearchOptions{
+		Language:     language,
+		CreatedAfter: client.now().Add(-window),
+		Sort:         "stars",
+		Order:        "desc",
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	result.Fallback = true
+	return result, nil
+}
+
+func (client *Client) fetchTrendingRepository(ctx context.Context, language string, since string) (*Result, error) {
+	if client.TrendingProvider != nil {
+		items, err := client.TrendingProvider.TrendingRepositories(ctx, language, since)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if items[i].DataSource == "" {
+				items[i].DataSource = SourceTrending
+			}
+		}
+		return &Result{TotalCount: len(items), Items: items}, nil
+	}
+	url := trendingURL(client.TrendingRepositoryURL, language, since)
+	req, err := client.newRequest(ctx, "GET", url.String())
 	if err != nil {
 		return nil, err
 	}
 	var result *Result
-	if err = json.Unmarshal(body, &result); err != nil {
+	if _, _, err := client.do(req, trendingDecoder(&result)); err != nil {
 		return nil, err
 	}
+	if result == nil {
+		return nil, errors.New("trending: empty response")
+	}
 	for i := range result.Items {
-		result.Items[i].DataSource = "TrendingAPI"
+		result.Items[i].DataSource = SourceTrending
+	}
+	return result, nil
+}
+
+const trendingHealthTimeout = 5 * time.Second
+
+// TrendingHealthy sends a single HEAD request to the trending backend,
+// without retries or caching, and reports whether it answered with a 2xx.
+// When ctx has no deadline the check gives up after five seconds. The error
+// explains why the backend is considered unavailable. With a
+// TrendingProvider set, the provider's TrendingHealthChecker is used if it
+// implements one; otherwise the provider is reported healthy.
+func (client *Client) TrendingHealthy(ctx context.Context) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, trendingHealthTimeout)
+		defer cancel()
+	}
+	if client.TrendingProvider != nil {
+		if checker, ok := client.TrendingProvider.(TrendingHealthChecker); ok {
+			return checker.TrendingHealthy(ctx)
+		}
+		return true, nil
+	}
+	req, err := client.newRequest(ctx, "HEAD", client.TrendingRepositoryURL.String())
+	if err != nil {
+		return false, err
+	}
+	if _, _, err := client.doOnce(req); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (client *Client) GetTrendingDevelopers(language string, since string) (*DeveloperResult, error) {
+	return client.GetTrendingDevelopersContext(context.Background(), language, since)
+}
+
+func (client *Client) GetTrendingDevelopersContext(ctx context.Context, language string, since string) (*DeveloperResult, error) {
+	if err := validateSince(since); err != nil {
+		return nil, err
+	}
+	url := trendingURL(client.TrendingDeveloperURL, language, since)
+	req, err := client.newRequest(ctx, "GET", url.String())
+	if err != nil {
+		return nil, err
+	}
+	var result *DeveloperResult
+	if _, _, err := client.do(req, trendingDecoder(&result)); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errors.New("trending: empty response")
 	}
 	return result, nil
 }
+
+// decodeTrending guards against the trending backend answering with an
+// HTML error page, which it does while the service is asleep or down.
+func decodeTrending(resp *http.Response, body []byte, v interface{}) error {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return fmt.Errorf("trending: unexpected content type %q", contentType)
+		}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return errors.New("trending: empty response")
+	}
+	if err := decodeJSON(resp, body, v); err != nil {
+		return fmt.Errorf("trending: malformed response: %w", err)
+	}
+	return nil
+}
+
+func validateSince(since string) error {
+	switch since {
+	case "", "daily", "weekly", "monthly":
+		return nil
+	default:
+		return fmt.Errorf("invalid since %q: must be daily, weekly or monthly", since)
+	}
+}
+
+func trendingURL(base *url.URL, language string, since string) *url.URL {
+	url := *base
+	q := url.Query()
+	if language != "" {
+		q.Set("lang", language)
+	}
+	if since != "" {
+		q.Set("since", since)
+	}
+	if len(q) != 0 {
+		url.RawQuery = q.Encode()
+	}
+	return &url
+}
@@ -0,0 +1,610 @@
+package lib_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestRequestInterceptors(t *testing.T) {
+	var headers http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": recordHeaders(&headers, `{"items":[]}`),
+	})
+	defer server.Close()
+	client.RequestInterceptors = []lib.RequestInterceptor{
+		func(req *http.Request) { req.Header.Set("X-Trace-Id", "abc123") },
+	}
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("X-Trace-Id"); got != "abc123" {
+		t.Errorf("X-Trace-Id = %q, want abc123", got)
+	}
+	if got := headers.Get("Accept"); got != "application/vnd.github+json" {
+		t.Errorf("Accept = %q, want the default media type", got)
+	}
+
+	client.RequestInterceptors = append(client.RequestInterceptors, lib.AcceptHeader("application/vnd.github.custom+json"))
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Accept"); got != "application/vnd.github.custom+json" {
+		t.Errorf("Accept = %q, want the interceptor's media type", got)
+	}
+}
+
+func TestTokenSetsAuthorization(t *testing.T) {
+	var headers http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": recordHeaders(&headers, `{"items":[]}`),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Authorization"); got != "" {
+		t.Errorf("Authorization without a token = %q, want none", got)
+	}
+
+	client.Token = "secret"
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+	}
+}
+
+func TestSearchRepositoryReturnsTransportErrors(t *testing.T) {
+	dialErr := errors.New("connection refused")
+	client, err := lib.NewClient(lib.WithHTTPClient(&http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, dialErr }),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.SearchRepository("go")
+	if !errors.Is(err, dialErr) {
+		t.Errorf("err = %v, want %v", err, dialErr)
+	}
+	if result != nil {
+		t.Errorf("result = %+v, want nil", result)
+	}
+}
+
+func TestSearchRepositoryEscapesQuery(t *testing.T) {
+	var rawQuery string
+	var q string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawQuery = r.URL.RawQuery
+			q = r.URL.Query().Get("q")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[]}`))
+		}),
+	})
+	defer server.Close()
+
+	query := `terminal ui language:go stars:>100 "c++&"`
+	if _, err := client.SearchRepositoryWithOptions(query, lib.SearchOptions{IncludeForks: true, IncludeArchived: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := query + " fork:true"; q != want {
+		t.Errorf("q = %q, want %q", q, want)
+	}
+	if strings.ContainsAny(rawQuery, ` "><`) || strings.Contains(rawQuery, "c++&") {
+		t.Errorf("raw query %q is not escaped", rawQuery)
+	}
+}
+
+func TestContextCancellationReturnsPromptly(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		}),
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	start := time.Now()
+	_, err := client.SearchRepositoryContext(ctx, "go")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("cancelled call took %v", elapsed)
+	}
+}
+
+// pagedSearchHandler serves a two-item search one item per page.
+func pagedSearchHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "1" {
+			t.Errorf("per_page = %q, want 1", got)
+		}
+		name := "owner/first"
+		if r.URL.Query().Get("page") == "2" {
+			name = "owner/second"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"total_count":2,"items":[{"full_name":%q}]}`, name)
+	})
+}
+
+func TestSearchRepositoryPagination(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{"/search/repositories": pagedSearchHandler(t)})
+	defer server.Close()
+
+	first, err := client.SearchRepositoryWithOptions("go", lib.SearchOptions{Page: 1, PerPage: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Items) != 1 || first.Items[0].GetRepositoryName() != "owner/first" || !first.HasNextPage() {
+		t.Fatalf("page 1 = %+v, want owner/first with a next page", first)
+	}
+	second, err := client.SearchRepositoryWithOptions("go", lib.SearchOptions{Page: 2, PerPage: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Items) != 1 || second.Items[0].GetRepositoryName() != "owner/second" || second.HasNextPage() {
+		t.Fatalf("page 2 = %+v, want owner/second and no next page", second)
+	}
+}
+
+func TestSearchRepositorySortAndOrder(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[]}`))
+		}),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchRepositoryWithOptions("go", lib.SearchOptions{Sort: "stars", Order: "desc"}); err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("sort") != "stars" || query.Get("order") != "desc" {
+		t.Errorf("query = %v, want sort=stars and order=desc", query)
+	}
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := query["sort"]; ok {
+		t.Errorf("query = %v, want no sort by default", query)
+	}
+	if _, ok := query["order"]; ok {
+		t.Errorf("query = %v, want no order by default", query)
+	}
+
+	if _, err := client.SearchRepositoryWithOptions("go", lib.SearchOptions{Order: "up"}); err == nil {
+		t.Error("invalid order was accepted")
+	}
+}
+
+func TestDrawWithoutColor(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleResult().DrawWithOptions(&buf, lib.DrawOptions{NoColor: true}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("output contains escape codes: %q", buf.String())
+	}
+	for _, name := range []string{"ryo-ma/lazyhub", "jroimartin/gocui"} {
+		if !strings.Contains(buf.String(), name) {
+			t.Errorf("output %q does not contain %s", buf.String(), name)
+		}
+	}
+
+	buf.Reset()
+	if err := sampleResult().Draw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\033[32m") {
+		t.Errorf("Draw output %q is not colored", buf.String())
+	}
+}
+
+func TestNewDrawOptionsDisablesColorForBuffers(t *testing.T) {
+	if opts := lib.NewDrawOptions(&bytes.Buffer{}); !opts.NoColor {
+		t.Error("NewDrawOptions enabled color for a non-terminal writer")
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub": jsonBody(`{
+			"full_name": "ryo-ma/lazyhub",
+			"html_url": "https://github.com/ryo-ma/lazyhub",
+			"description": "lazyhub is a terminal UI client for GitHub",
+			"stargazers_count": 1200,
+			"topics": ["cli", "tui"],
+			"language": "Go"
+		}`),
+	})
+	defer server.Close()
+
+	item, err := client.GetRepository("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.DataSource != lib.SourceOfficial {
+		t.Errorf("DataSource = %q, want %q", item.DataSource, lib.SourceOfficial)
+	}
+	if item.GetRepositoryName() != "ryo-ma/lazyhub" || item.GetStars() != 1200 || item.Language != "Go" || len(item.Topics) != 2 {
+		t.Errorf("item = %+v", item)
+	}
+
+	_, err = client.GetRepository("ryo-ma", "missing")
+	if !lib.IsNotFound(err) {
+		t.Errorf("missing repository: err = %v, want not found", err)
+	}
+}
+
+func TestSearchRepositoryCounts(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": jsonBody(`{"total_count":4321,"incomplete_results":true,"items":[{"full_name":"ryo-ma/lazyhub"}]}`),
+	})
+	defer server.Close()
+
+	result, err := client.SearchRepository("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalCount != 4321 || !result.IncompleteResults {
+		t.Errorf("TotalCount = %d, IncompleteResults = %v; want 4321, true", result.TotalCount, result.IncompleteResults)
+	}
+}
+
+func TestGetRepositoryTopics(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/topics": jsonBody(`{"names":["cli","github","tui"]}`),
+	})
+	defer server.Close()
+
+	topics, err := client.GetRepositoryTopics("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cli", "github", "tui"}; !reflect.DeepEqual(topics, want) {
+		t.Errorf("topics = %v, want %v", topics, want)
+	}
+}
+
+func TestGetRepositoryName(t *testing.T) {
+	tests := []struct {
+		item lib.Item
+		want string
+	}{
+		{lib.Item{FullName: "ryo-ma/lazyhub", Name: "lazyhub"}, "ryo-ma/lazyhub"},
+		{lib.Item{Name: " ryo-ma / lazyhub "}, "ryo-ma/lazyhub"},
+		{lib.Item{URL: "https://github.com/ryo-ma/lazyhub"}, "ryo-ma/lazyhub"},
+		{lib.Item{URL: "https://github.com/ryo-ma/lazyhub/", Name: "ryo-ma / other"}, "ryo-ma/lazyhub"},
+	}
+	for _, test := range tests {
+		if got := test.item.GetRepositoryName(); got != test.want {
+			t.Errorf("GetRepositoryName(%+v) = %q, want %q", test.item, got, test.want)
+		}
+	}
+	item := lib.Item{Name: "ryo-ma / lazyhub"}
+	if item.GetOwner() != "ryo-ma" || item.GetRepo() != "lazyhub" {
+		t.Errorf("GetOwner, GetRepo = %q, %q", item.GetOwner(), item.GetRepo())
+	}
+}
+
+func TestGetRepositoryETag(t *testing.T) {
+	var ifNoneMatch []string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			jsonBody(`{"full_name":"ryo-ma/lazyhub","stargazers_count":1200}`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	client.TrackETags = true
+
+	first, err := client.GetRepository("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.GetRepository("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", `"v1"`}; !reflect.DeepEqual(ifNoneMatch, want) {
+		t.Errorf("If-None-Match = %q, want %q", ifNoneMatch, want)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("304 item = %+v, want the cached %+v", second, first)
+	}
+}
+
+func TestGetCloneURL(t *testing.T) {
+	tests := []struct {
+		item lib.Item
+		want string
+	}{
+		{lib.Item{CloneURL: "https://github.com/ryo-ma/lazyhub.git", HTMLURL: "https://github.com/ryo-ma/other"}, "https://github.com/ryo-ma/lazyhub.git"},
+		{lib.Item{HTMLURL: "https://github.com/ryo-ma/lazyhub"}, "https://github.com/ryo-ma/lazyhub.git"},
+		{lib.Item{URL: "https://github.com/ryo-ma/lazyhub"}, "https://github.com/ryo-ma/lazyhub.git"},
+		{lib.Item{URL: "https://github.com/ryo-ma/lazyhub.git"}, "https://github.com/ryo-ma/lazyhub.git"},
+	}
+	for _, test := range tests {
+		if got := test.item.GetCloneURL(); got != test.want {
+			t.Errorf("GetCloneURL(%+v) = %q, want %q", test.item, got, test.want)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	client.Token = "secret"
+	var lines []map[string]interface{}
+	client.Logger = func(msg string, keyvals ...interface{}) {
+		line := map[string]interface{}{"msg": msg}
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			line[keyvals[i].(string)] = keyvals[i+1]
+		}
+		lines = append(lines, line)
+	}
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	client.GetRepository("owner", "missing")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2", len(lines))
+	}
+	if url, _ := lines[0]["url"].(string); !strings.HasPrefix(url, server.URL+"/search/repositories?") {
+		t.Errorf("url = %v, want the search URL", lines[0]["url"])
+	}
+	if lines[0]["method"] != "GET" || lines[0]["status"] != http.StatusOK {
+		t.Errorf("first line = %v, want GET with status 200", lines[0])
+	}
+	if lines[1]["status"] != http.StatusNotFound {
+		t.Errorf("second line = %v, want status 404", lines[1])
+	}
+	if _, ok := lines[0]["duration"].(time.Duration); !ok {
+		t.Errorf("duration = %v, want a time.Duration", lines[0]["duration"])
+	}
+	if logged := fmt.Sprint(lines); strings.Contains(logged, "secret") {
+		t.Errorf("log contains the token: %s", logged)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var searchHeaders, trendingHeaders http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": recordHeaders(&searchHeaders, lazyhubtest.SearchResponse),
+		"/repo":                recordHeaders(&trendingHeaders, lazyhubtest.TrendingResponse),
+	})
+	defer server.Close()
+	client.UserAgent = "my-dashboard/2.0"
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTrendingRepository("go", ""); err != nil {
+		t.Fatal(err)
+	}
+	for source, headers := range map[string]http.Header{"search": searchHeaders, "trending": trendingHeaders} {
+		if got := headers.Get("User-Agent"); got != "my-dashboard/2.0" {
+			t.Errorf("%s User-Agent = %q, want my-dashboard/2.0", source, got)
+		}
+	}
+}
+
+func TestStringTemplatePerSource(t *testing.T) {
+	tests := []struct {
+		source       lib.DataSource
+		wantWatchers bool
+	}{
+		{lib.SourceOfficial, true},
+		{lib.SourceGraphQL, true},
+		{lib.SourceTrending, false},
+		{"", false},
+	}
+	for _, test := range tests {
+		item := lib.Item{FullName: "ryo-ma/lazyhub", Watchers: 30, DataSource: test.source}
+		if got := strings.Contains(item.String(), "Watchers"); got != test.wantWatchers {
+			t.Errorf("%q: String() includes Watchers = %v, want %v:\n%s", test.source, got, test.wantWatchers, item.String())
+		}
+	}
+}
+
+func TestStringStarsPerSource(t *testing.T) {
+	tests := []struct {
+		name string
+		item lib.Item
+		want string
+	}{
+		{"official", lib.Item{FullName: "a/b", StargazersCount: 1200, DataSource: lib.SourceOfficial}, "⭐️ 1200"},
+		{"trending", lib.Item{Name: "a / b", Stars: "1,200", DataSource: lib.SourceTrending}, "⭐️ 1200"},
+		{"unset", lib.Item{Name: "a / b", Stars: "1,200"}, "⭐️ 1200"},
+		{"unset official", lib.Item{FullName: "a/b", StargazersCount: 1200}, "⭐️ 1200"},
+		{"official with both", lib.Item{FullName: "a/b", StargazersCount: 1300, Stars: "1,200", DataSource: lib.SourceOfficial}, "⭐️ 1300"},
+		{"trending with both", lib.Item{Name: "a / b", StargazersCount: 1300, Stars: "1,200", DataSource: lib.SourceTrending}, "⭐️ 1200"},
+	}
+	for _, test := range tests {
+		if got := test.item.String(); !strings.Contains(got, test.want+"\n") {
+			t.Errorf("%s: String() = %q, want it to contain %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGetOwnerAndRepo(t *testing.T) {
+	tests := []struct {
+		item  lib.Item
+		owner string
+		repo  string
+	}{
+		{lib.Item{FullName: "ryo-ma/lazyhub"}, "ryo-ma", "lazyhub"},
+		{lib.Item{Name: "ryo-ma / lazyhub"}, "ryo-ma", "lazyhub"},
+		{lib.Item{FullName: "lazyhub"}, "", "lazyhub"},
+		{lib.Item{}, "", ""},
+	}
+	for _, test := range tests {
+		if owner, repo := test.item.GetOwner(), test.item.GetRepo(); owner != test.owner || repo != test.repo {
+			t.Errorf("%+v: GetOwner, GetRepo = %q, %q; want %q, %q", test.item, owner, repo, test.owner, test.repo)
+		}
+	}
+}
+
+func TestDefaultUserAgentIncludesVersion(t *testing.T) {
+	var headers http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": recordHeaders(&headers, `{"items":[]}`),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if lib.Version() == "" {
+		t.Fatal("Version() is empty")
+	}
+	if got, want := headers.Get("User-Agent"), "LazyHub/"+lib.Version(); got != want || lib.DefaultUserAgent != want {
+		t.Errorf("User-Agent = %q, DefaultUserAgent = %q; want %q", got, lib.DefaultUserAgent, want)
+	}
+}
+
+func TestGetStarsParsesTrendingCounts(t *testing.T) {
+	tests := []struct {
+		stars string
+		want  int
+	}{
+		{"1,234", 1234},
+		{"12k", 12000},
+		{"12.3K", 12300},
+		{"1.2M", 1200000},
+		{"987", 987},
+		{" 42 ", 42},
+		{"", 0},
+		{"n/a", 0},
+	}
+	for _, test := range tests {
+		item := lib.Item{Stars: test.stars, DataSource: lib.SourceTrending}
+		if got := item.GetStars(); got != test.want {
+			t.Errorf("GetStars(%q) = %d, want %d", test.stars, got, test.want)
+		}
+	}
+}
+
+func TestFormattedStars(t *testing.T) {
+	tests := []struct {
+		stars int
+		want  string
+	}{
+		{987, "987"},
+		{1000, "1k"},
+		{1234, "1.2k"},
+		{12300, "12.3k"},
+		{999949, "999.9k"},
+		{999950, "1M"},
+		{3400000, "3.4M"},
+	}
+	for _, test := range tests {
+		item := lib.Item{StargazersCount: test.stars, DataSource: lib.SourceOfficial}
+		if got := item.FormattedStars(); got != test.want {
+			t.Errorf("FormattedStars(%d) = %q, want %q", test.stars, got, test.want)
+		}
+	}
+}
+
+func TestDrawPaged(t *testing.T) {
+	tests := []struct {
+		result  lib.Result
+		page    int
+		perPage int
+		want    string
+	}{
+		{lib.Result{TotalCount: 1012}, 2, 30, "Page 2 of 34 (1012 results)\n"},
+		{lib.Result{TotalCount: 60}, 2, 30, "Page 2 of 2 (60 results)\n"},
+		{lib.Result{}, 0, 0, "Page 1 of 1 (0 results)\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := test.result.DrawPaged(&buf, test.page, test.perPage); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("DrawPaged(%d, %d) with %d results = %q, want %q", test.page, test.perPage, test.result.TotalCount, got, test.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	result := sampleResult()
+	result.TotalCount = 2
+	if err := result.DrawPaged(&buf, 1, 30); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[2] != "Page 1 of 1 (2 results)" {
+		t.Errorf("DrawPaged output = %q, want two items and the footer", buf.String())
+	}
+
+	buf.Reset()
+	if err := result.DrawPagedWithOptions(&buf, 1, 30, lib.DrawOptions{NoColor: true}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\033[") || !strings.HasSuffix(buf.String(), "Page 1 of 1 (2 results)\n") {
+		t.Errorf("DrawPagedWithOptions(NoColor) = %q, want plain items and the footer", buf.String())
+	}
+}
+
+func TestContextDeadlineBeatsClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}),
+	})
+	defer server.Close()
+	defer close(release)
+	client.SetTimeout(time.Minute)
+	client.Retry = lib.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.SearchRepositoryContext(ctx, "go")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("call took %v, want the context deadline to win", elapsed)
+	}
+}
@@ -0,0 +1,152 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const graphQLSearchQuery = `query($query: String!, $first: Int!) {
+  search(query: $query, type: REPOSITORY, first: $first) {
+    repositoryCount
+    nodes {
+      ... on Repository {
+        databaseId
+        name
+        nameWithOwner
+        url
+        description
+        stargazerCount
+        watchers { totalCount }
+        primaryLanguage { name }
+        defaultBranchRef { name }
+        createdAt
+        updatedAt
+        repositoryTopics(first: 20) { nodes { topic { name } } }
+      }
+    }
+  }
+}`
+
+type graphQLRepository struct {
+	DatabaseID     int    `json:"databaseId"`
+	Name           string `json:"name"`
+	NameWithOwner  string `json:"nameWithOwner"`
+	URL            string `json:"url"`
+	Description    string `json:"description"`
+	StargazerCount int    `json:"stargazerCount"`
+	Watchers       struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"watchers"`
+	PrimaryLanguage *struct {
+		Name string `json:"name"`
+	} `json:"primaryLanguage"`
+	DefaultBranchRef *struct {
+		Name string `json:"name"`
+	} `json:"defaultBranchRef"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+}
+
+type graphQLSearchResponse struct {
+	Data struct {
+		Search struct {
+			RepositoryCount int                 `json:"repositoryCount"`
+			Nodes           []graphQLRepository `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (repository *graphQLRepository) item() Item {
+	item := Item{
+		ID:              repository.DatabaseID,
+		Name:            repository.Name,
+		FullName:        repository.NameWithOwner,
+		HTMLURL:         repository.URL,
+		CloneURL:        repository.URL + ".git",
+		Description:     repository.Description,
+		StargazersCount: repository.StargazerCount,
+		Watchers:        repository.Watchers.TotalCount,
+		CreatedAt:       repository.CreatedAt,
+		UpdatedAt:       repository.UpdatedAt,
+		DataSource:      SourceGraphQL,
+	}
+	if repository.PrimaryLanguage != nil {
+		item.Language = repository.PrimaryLanguage.Name
+	}
+	if repository.DefaultBranchRef != nil {
+		item.DefaultBranch = repository.DefaultBranchRef.Name
+	}
+	for _, node := range repository.RepositoryTopics.Nodes {
+		item.Topics = append(item.Topics, node.Topic.Name)
+	}
+	return item
+}
+
+func (client *Client) graphQLURL() string {
+	url := *client.OfficialURL
+	// GitHub Enterprise serves REST under /api/v3 but GraphQL under /api/graphql.
+	if strings.HasSuffix(strings.TrimSuffix(url.Path, "/"), "/api/v3") {
+		url.Path = strings.TrimSuffix(strings.TrimSuffix(url.Path, "/"), "/v3")
+	}
+	url.Path = strings.TrimSuffix(url.Path, "/") + "/graphql"
+	return url.String()
+}
+
+func (client *Client) SearchRepositoryGraphQL(query string, first int) (*Result, error) {
+	return client.SearchRepositoryGraphQLContext(context.Background(), query, first)
+}
+
+// SearchRepositoryGraphQLContext searches through the GraphQL API, which
+// requires Token to be set and has its own rate limit budget.
+func (client *Client) SearchRepositoryGraphQLContext(ctx context.Context, query string, first int) (*Result, error) {
+	if client.Token == "" {
+		return nil, errors.New("graphql search requires a token")
+	}
+	if first <= 0 {
+		first = defaultPerPage
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"query": graphQLSearchQuery,
+		"variables": map[string]interface{}{
+			"query": query,
+			"first": first,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.newRequestWithBody(ctx, "POST", client.graphQLURL(), "", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var response graphQLSearchResponse
+	if _, _, err := client.do(req, jsonDecoder(&response)); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) != 0 {
+		messages := make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			messages = append(messages, e.Message)
+		}
+		return nil, errors.New("graphql: " + strings.Join(messages, "; "))
+	}
+	result := &Result{TotalCount: response.Data.Search.RepositoryCount, PerPage: first}
+	for i := range response.Data.Search.Nodes {
+		result.Items = append(result.Items, response.Data.Search.Nodes[i].item())
+	}
+	return result, nil
+}
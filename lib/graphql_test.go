@@ -0,0 +1,107 @@
+package lib_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestSearchRepositoryGraphQL(t *testing.T) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	var headers http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/graphql": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Error(err)
+			}
+			jsonBody(`{"data":{"search":{"repositoryCount":1,"nodes":[{
+				"databaseId": 1,
+				"name": "lazyhub",
+				"nameWithOwner": "ryo-ma/lazyhub",
+				"url": "https://github.com/ryo-ma/lazyhub",
+				"description": "lazyhub is a terminal UI client for GitHub",
+				"stargazerCount": 1200,
+				"watchers": {"totalCount": 30},
+				"primaryLanguage": {"name": "Go"},
+				"defaultBranchRef": {"name": "master"},
+				"repositoryTopics": {"nodes": [{"topic": {"name": "cli"}}, {"topic": {"name": "tui"}}]}
+			}]}}}`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchRepositoryGraphQL("go", 5); err == nil {
+		t.Error("want an error without a token")
+	}
+	client.Token = "secret"
+	result, err := client.SearchRepositoryGraphQL("tui language:go", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Variables["query"] != "tui language:go" || request.Variables["first"] != float64(5) {
+		t.Errorf("variables = %v", request.Variables)
+	}
+	if headers.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q", headers.Get("Authorization"))
+	}
+	want := lib.Item{
+		ID:              1,
+		Name:            "lazyhub",
+		FullName:        "ryo-ma/lazyhub",
+		HTMLURL:         "https://github.com/ryo-ma/lazyhub",
+		CloneURL:        "https://github.com/ryo-ma/lazyhub.git",
+		Description:     "lazyhub is a terminal UI client for GitHub",
+		StargazersCount: 1200,
+		Watchers:        30,
+		Topics:          []string{"cli", "tui"},
+		Language:        "Go",
+		DefaultBranch:   "master",
+		DataSource:      lib.SourceGraphQL,
+	}
+	if result.TotalCount != 1 || len(result.Items) != 1 || !reflect.DeepEqual(result.Items[0], want) {
+		t.Errorf("result = %+v, want one item %+v", result, want)
+	}
+}
+
+func TestSearchRepositoryGraphQLErrors(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/graphql": jsonBody(`{"errors":[{"message":"Something went wrong"}]}`),
+	})
+	defer server.Close()
+	client.Token = "secret"
+
+	_, err := client.SearchRepositoryGraphQL("go", 5)
+	if err == nil || !strings.Contains(err.Error(), "Something went wrong") {
+		t.Errorf("err = %v, want the GraphQL error message", err)
+	}
+}
+
+func TestSearchRepositoryGraphQLEnterpriseURL(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"search":{"nodes":[]}}}`))
+	}))
+	defer server.Close()
+	client, err := lib.NewClient(lib.WithBaseURL(server.URL+"/api/v3"), lib.WithToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.SearchRepositoryGraphQL("go", 5); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/api/graphql" {
+		t.Errorf("path = %q, want /api/graphql", path)
+	}
+}
@@ -0,0 +1,45 @@
+package lib_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func newTestClient(t *testing.T, handlers map[string]http.Handler) (*lib.Client, *lazyhubtest.Server) {
+	t.Helper()
+	server := lazyhubtest.NewServer()
+	for path, handler := range handlers {
+		server.Handle(path, handler)
+	}
+	client, err := server.Client()
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+func jsonBody(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+// recordHeaders serves body and stores the headers of the last request.
+func recordHeaders(headers *http.Header, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
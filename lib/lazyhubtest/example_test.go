@@ -0,0 +1,62 @@
+package lazyhubtest_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func ExampleNewServer() {
+	server := lazyhubtest.NewServer()
+	defer server.Close()
+	client, err := server.Client()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	result, err := client.SearchRepository("lazyhub")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(result.Items[0].GetRepositoryName())
+
+	readme, err := client.GetReadme(result.Items[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	content, _ := readme.DecodedContent()
+	fmt.Print(content)
+	// Output:
+	// ryo-ma/lazyhub
+	// # lazyhub
+	//
+	// lazyhub is a terminal UI client for GitHub.
+}
+
+func ExampleServer_Handle() {
+	server := lazyhubtest.NewServer()
+	defer server.Close()
+	server.Handle("/repos/ryo-ma/lazyhub", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"full_name":"ryo-ma/lazyhub","stargazers_count":42}`))
+	}))
+	client, _ := server.Client()
+
+	item, err := client.GetRepository("ryo-ma", "lazyhub")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(item.GetRepositoryName(), item.GetStars())
+
+	_, err = client.GetRepository("ryo-ma", "missing")
+	fmt.Println(lib.IsNotFound(err))
+	// Output:
+	// ryo-ma/lazyhub 42
+	// true
+}
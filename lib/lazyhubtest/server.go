@@ -0,0 +1,143 @@
+// Package lazyhubtest provides an in-memory GitHub and trending backend for
+// tests and examples. NewServer serves canned search, trending, developer
+// and README responses; Handle replaces or adds routes.
+package lazyhubtest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+const SearchResponse = `{
+  "total_count": 2,
+  "incomplete_results": false,
+  "items": [
+    {
+      "id": 1,
+      "name": "lazyhub",
+      "full_name": "ryo-ma/lazyhub",
+      "html_url": "https://github.com/ryo-ma/lazyhub",
+      "clone_url": "https://github.com/ryo-ma/lazyhub.git",
+      "description": "lazyhub is a terminal UI client for GitHub",
+      "stargazers_count": 1200,
+      "watchers": 1200,
+      "topics": ["cli", "github", "tui"],
+      "language": "Go",
+      "default_branch": "master",
+      "created_at": "2020-01-01T00:00:00Z",
+      "updated_at": "2020-06-01T00:00:00Z"
+    },
+    {
+      "id": 2,
+      "name": "gocui",
+      "full_name": "jroimartin/gocui",
+      "html_url": "https://github.com/jroimartin/gocui",
+      "clone_url": "https://github.com/jroimartin/gocui.git",
+      "description": "Minimalist Go package aimed at creating Console User Interfaces.",
+      "stargazers_count": 8000,
+      "watchers": 8000,
+      "topics": ["go", "tui"],
+      "language": "Go",
+      "default_branch": "master",
+      "created_at": "2014-01-01T00:00:00Z",
+      "updated_at": "2020-05-01T00:00:00Z"
+    }
+  ]
+}`
+
+const TrendingResponse = `{
+  "items": [
+    {
+      "repo": "ryo-ma / lazyhub",
+      "repo_link": "https://github.com/ryo-ma/lazyhub",
+      "desc": "lazyhub is a terminal UI client for GitHub",
+      "lang": "Go",
+      "stars": "1,200"
+    }
+  ]
+}`
+
+const DevelopersResponse = `{
+  "items": [
+    {
+      "user": "ryo-ma",
+      "full_name": "ryo-ma",
+      "user_link": "https://github.com/ryo-ma",
+      "developer_avatar": "https://avatars.githubusercontent.com/u/1",
+      "repo": "lazyhub",
+      "repo_link": "https://github.com/ryo-ma/lazyhub",
+      "desc": "lazyhub is a terminal UI client for GitHub"
+    }
+  ]
+}`
+
+const Readme = "# lazyhub\n\nlazyhub is a terminal UI client for GitHub.\n"
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+}
+
+func NewServer() *Server {
+	server := &Server{handlers: map[string]http.Handler{}}
+	server.Handle("/search/repositories", jsonHandler(SearchResponse))
+	server.Handle("/repo", jsonHandler(TrendingResponse))
+	server.Handle("/dev", jsonHandler(DevelopersResponse))
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+// Handle registers handler for an exact path, replacing any canned
+// response for it.
+func (server *Server) Handle(path string, handler http.Handler) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.handlers[path] = handler
+}
+
+func (server *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	handler, ok := server.handlers[r.URL.Path]
+	server.mu.Unlock()
+	if ok {
+		handler.ServeHTTP(w, r)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/repos/") && strings.HasSuffix(r.URL.Path, "/readme") {
+		jsonHandler(readmeResponse()).ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (server *Server) Client() (*lib.Client, error) {
+	return lib.NewClient(
+		lib.WithBaseURL(server.URL),
+		lib.WithTrendingURL(server.URL+"/repo"),
+		lib.WithTrendingDeveloperURL(server.URL+"/dev"),
+		lib.WithHTTPClient(server.Server.Client()),
+	)
+}
+
+func readmeResponse() string {
+	return `{
+  "name": "README.md",
+  "path": "README.md",
+  "encoding": "base64",
+  "content": "` + base64.StdEncoding.EncodeToString([]byte(Readme)) + `"
+}`
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
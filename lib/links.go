@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"strings"
+)
+
+type Links struct {
+	First string
+	Prev  string
+	Next  string
+	Last  string
+}
+
+// ParseLinkHeader parses an RFC 5988 Link header such as
+// `<https://api.github.com/...&page=2>; rel="next", <...>; rel="last"`.
+func ParseLinkHeader(header string) Links {
+	var links Links
+	for _, part := range strings.Split(header, ",") {
+		sections := strings.Split(part, ";")
+		target := strings.TrimSpace(sections[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		target = target[1 : len(target)-1]
+		for _, param := range sections[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "rel=") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimPrefix(param, "rel="), `"`)) {
+				switch rel {
+				case "first":
+					links.First = target
+				case "prev":
+					links.Prev = target
+				case "next":
+					links.Next = target
+				case "last":
+					links.Last = target
+				}
+			}
+		}
+	}
+	return links
+}
+
+func (links Links) empty() bool {
+	return links == Links{}
+}
@@ -0,0 +1,60 @@
+package lib_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	header := `<https://api.github.com/search/repositories?q=go&page=2>; rel="next", ` +
+		`<https://api.github.com/search/repositories?q=go&page=34>; rel="last"`
+	want := lib.Links{
+		Next: "https://api.github.com/search/repositories?q=go&page=2",
+		Last: "https://api.github.com/search/repositories?q=go&page=34",
+	}
+	if got := lib.ParseLinkHeader(header); got != want {
+		t.Errorf("ParseLinkHeader = %+v, want %+v", got, want)
+	}
+
+	header = `<https://api.github.com/x?page=1>; rel="first prev", <https://api.github.com/x?page=3>;rel=next, garbage`
+	want = lib.Links{
+		First: "https://api.github.com/x?page=1",
+		Prev:  "https://api.github.com/x?page=1",
+		Next:  "https://api.github.com/x?page=3",
+	}
+	if got := lib.ParseLinkHeader(header); got != want {
+		t.Errorf("ParseLinkHeader = %+v, want %+v", got, want)
+	}
+	if got := lib.ParseLinkHeader(""); got != (lib.Links{}) {
+		t.Errorf("ParseLinkHeader(\"\") = %+v, want no links", got)
+	}
+}
+
+func TestSearchIteratorFollowsLinkHeader(t *testing.T) {
+	var pages []string
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	server.Handle("/search/repositories", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		if r.URL.Query().Get("cursor") == "" {
+			w.Header().Set("Link", `<`+server.URL+`/search/repositories?q=go&cursor=abc&page=2>; rel="next"`)
+		} else {
+			w.Header().Set("Link", `<`+server.URL+`/search/repositories?q=go&page=1>; rel="prev first"`)
+		}
+		jsonBody(`{"total_count":1000,"items":[{"full_name":"owner/repo"}]}`).ServeHTTP(w, r)
+	}))
+
+	it := client.SearchRepositoryIter("go", lib.SearchOptions{PerPage: 1})
+	for {
+		if _, err := it.Next(context.Background()); err != nil {
+			break
+		}
+	}
+	if want := []string{"1", "2"}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %q, want the iterator to follow the Link header and stop without a next relation", pages)
+	}
+}
@@ -0,0 +1,137 @@
+package lib
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Option func(*Client) error
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) error {
+		if httpClient == nil {
+			return errors.New("http client must not be nil")
+		}
+		client.HTTPClient = httpClient
+		return nil
+	}
+}
+
+func WithToken(token string) Option {
+	return func(client *Client) error {
+		client.Token = token
+		return nil
+	}
+}
+
+func WithBaseURL(baseURL string) Option {
+	return func(client *Client) error {
+		officialURL, err := parseBaseURL(baseURL)
+		if err != nil {
+			return err
+		}
+		client.OfficialURL = officialURL
+		return nil
+	}
+}
+
+func WithTrendingURL(trendingURL string) Option {
+	return func(client *Client) error {
+		trendingRepositoryURL, err := parseBaseURL(trendingURL)
+		if err != nil {
+			return err
+		}
+		client.TrendingRepositoryURL = trendingRepositoryURL
+		return nil
+	}
+}
+
+func WithTrendingDeveloperURL(trendingURL string) Option {
+	return func(client *Client) error {
+		trendingDeveloperURL, err := parseBaseURL(trendingURL)
+		if err != nil {
+			return err
+		}
+		client.TrendingDeveloperURL = trendingDeveloperURL
+		return nil
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client) error {
+		client.SetTimeout(timeout)
+		return nil
+	}
+}
+
+func WithProxy(proxyURL string) Option {
+	return func(client *Client) error {
+		u, err := parseBaseURL(proxyURL)
+		if err != nil {
+			return err
+		}
+		return WithProxyFunc(http.ProxyURL(u))(client)
+	}
+}
+
+// WithProxyFunc routes requests through proxy. It copies the current HTTP
+// client and clones its transport, so a client passed to WithHTTPClient and
+// http.DefaultTransport are never modified. Options apply in order: a later
+// WithHTTPClient replaces the client and drops the proxy.
+func WithProxyFunc(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(client *Client) error {
+		httpClient := &http.Client{Timeout: DefaultTimeout}
+		if client.HTTPClient != nil && client.HTTPClient != http.DefaultClient {
+			copied := *client.HTTPClient
+			httpClient = &copied
+		}
+		var transport *http.Transport
+		switch base := httpClient.Transport.(type) {
+		case nil:
+			defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+			if !ok {
+				return errors.New("proxy requires the http client to use an *http.Transport")
+			}
+			transport = defaultTransport.Clone()
+		case *http.Transport:
+			transport = base.Clone()
+		default:
+			return errors.New("proxy requires the http client to use an *http.Transport")
+		}
+		transport.Proxy = proxy
+		httpClient.Transport = transport
+		client.HTTPClient = httpClient
+		return nil
+	}
+}
+
+func WithAccept(mediaType string) Option {
+	return func(client *Client) error {
+		client.Accept = mediaType
+		return nil
+	}
+}
+
+// WithPreview opts in to a GitHub API preview, e.g. WithPreview("mercy")
+// for application/vnd.github.mercy-preview+json.
+func WithPreview(name string) Option {
+	return func(client *Client) error {
+		if name == "" {
+			return errors.New("preview name must not be empty")
+		}
+		client.Previews = append(client.Previews, name)
+		return nil
+	}
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(client *Client) error {
+		if now == nil {
+			return errors.New("clock must not be nil")
+		}
+		client.Now = now
+		return nil
+	}
+}
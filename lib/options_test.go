@@ -0,0 +1,340 @@
+package lib_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestWithProxyDoesNotModifyCallerTransport(t *testing.T) {
+	callerTransport := &http.Transport{}
+	callerClient := &http.Client{Transport: callerTransport}
+	sharedClient := &http.Client{Transport: http.DefaultTransport}
+	nilTransportClient := &http.Client{}
+
+	for _, httpClient := range []*http.Client{callerClient, sharedClient, nilTransportClient} {
+		client, err := lib.NewClient(lib.WithHTTPClient(httpClient), lib.WithProxy("http://proxy.example:8080"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.HTTPClient == httpClient {
+			t.Error("WithProxy reused the caller's http.Client")
+		}
+		transport, ok := client.HTTPClient.Transport.(*http.Transport)
+		if !ok || transport.Proxy == nil {
+			t.Fatalf("transport = %#v, want an *http.Transport with a proxy", client.HTTPClient.Transport)
+		}
+		req, _ := http.NewRequest("GET", "https://api.github.com", nil)
+		proxyURL, err := transport.Proxy(req)
+		if err != nil || proxyURL == nil || proxyURL.Host != "proxy.example:8080" {
+			t.Errorf("proxy = %v, %v, want proxy.example:8080", proxyURL, err)
+		}
+	}
+
+	if callerTransport.Proxy != nil {
+		t.Error("WithProxy set Proxy on the caller's transport")
+	}
+	if nilTransportClient.Transport != nil {
+		t.Error("WithProxy set a transport on the caller's client")
+	}
+	if sharedClient.Transport != http.DefaultTransport {
+		t.Error("WithProxy replaced the caller's transport")
+	}
+	if proxy := http.DefaultTransport.(*http.Transport).Proxy; proxy != nil {
+		req, _ := http.NewRequest("GET", "https://api.github.com", nil)
+		if proxyURL, _ := proxy(req); proxyURL != nil && proxyURL.Host == "proxy.example:8080" {
+			t.Error("WithProxy changed http.DefaultTransport")
+		}
+	}
+}
+
+func TestWithProxyRejectsCustomTransport(t *testing.T) {
+	custom := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+	if _, err := lib.NewClient(lib.WithHTTPClient(custom), lib.WithProxyFunc(http.ProxyURL(&url.URL{Host: "p"}))); err == nil {
+		t.Error("WithProxyFunc accepted a non-*http.Transport")
+	}
+}
+
+func TestWithBaseURLEnterprise(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[],"full_name":"owner/repo"}`))
+	}))
+	defer server.Close()
+	client, err := lib.NewClient(lib.WithBaseURL(server.URL + "/api/v3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetRepository("owner", "repo"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/api/v3/search/repositories", "/api/v3/repos/owner/repo"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	for _, invalid := range []string{"github.example.com/api/v3", "://bad", "https://"} {
+		if _, err := lib.NewClient(lib.WithBaseURL(invalid)); err == nil {
+			t.Errorf("WithBaseURL(%q): want an error", invalid)
+		}
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	_, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}),
+	})
+	defer server.Close()
+	defer close(release)
+	mine := &http.Client{Timeout: time.Hour, Transport: server.Server.Client().Transport}
+	client, err := lib.NewClient(
+		lib.WithBaseURL(server.URL),
+		lib.WithHTTPClient(mine),
+		lib.WithTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mine.Timeout != time.Hour {
+		t.Errorf("caller's client Timeout = %v, want it left at 1h", mine.Timeout)
+	}
+	if client.HTTPClient == mine || client.HTTPClient.Transport != mine.Transport {
+		t.Error("want a copy of the caller's client that keeps its transport")
+	}
+
+	start := time.Now()
+	_, err = client.SearchRepository("go")
+	if err == nil {
+		t.Fatal("want a timeout error")
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v, want it to time out", elapsed)
+	}
+}
+
+func TestNewClientHasDefaultTimeout(t *testing.T) {
+	client, err := lib.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.HTTPClient == http.DefaultClient || client.HTTPClient.Timeout != lib.DefaultTimeout {
+		t.Errorf("HTTPClient = %+v, want a dedicated client with the default timeout", client.HTTPClient)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var calls int32
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"items":[]}`)),
+			Request:    req,
+		}, nil
+	})}
+	client, err := lib.NewClient(
+		lib.WithHTTPClient(httpClient),
+		lib.WithToken("secret"),
+		lib.WithBaseURL("https://github.example.com/api/v3"),
+		lib.WithTrendingURL("https://trending.example.com/repo"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.HTTPClient != httpClient || client.Token != "secret" {
+		t.Errorf("client = %+v, want the HTTP client and token from the options", client)
+	}
+	if client.OfficialURL.String() != "https://github.example.com/api/v3" || client.TrendingRepositoryURL.String() != "https://trending.example.com/repo" {
+		t.Errorf("OfficialURL = %s, TrendingRepositoryURL = %s", client.OfficialURL, client.TrendingRepositoryURL)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("custom HTTP client was used %d times, want 1", calls)
+	}
+
+	if _, err := lib.NewClient(lib.WithHTTPClient(nil)); err == nil {
+		t.Error("WithHTTPClient(nil): want an error")
+	}
+	if _, err := lib.NewClient(lib.WithTrendingURL("not a url")); err == nil {
+		t.Error("WithTrendingURL with an invalid URL: want an error")
+	}
+	defaults, err := lib.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults.OfficialURL.String() != "https://api.github.com" || defaults.Token != "" {
+		t.Errorf("NewClient() = %+v, want the defaults", defaults)
+	}
+}
+
+func TestWithProxyFuncIsUsed(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.Host)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer proxy.Close()
+	var calls int32
+	client, err := lib.NewClient(
+		lib.WithBaseURL("http://github.example.invalid/api/v3"),
+		lib.WithProxyFunc(func(req *http.Request) (*url.URL, error) {
+			atomic.AddInt32(&calls, 1)
+			return url.Parse(proxy.URL)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if calls == 0 {
+		t.Error("proxy function was not called")
+	}
+	if want := []string{"github.example.invalid"}; !reflect.DeepEqual(proxied, want) {
+		t.Errorf("proxied hosts = %v, want %v", proxied, want)
+	}
+}
+
+func TestNewClientUsesEnvironmentProxy(t *testing.T) {
+	client, err := lib.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Errorf("transport = %#v, want an *http.Transport with a proxy function", client.HTTPClient.Transport)
+	}
+}
+
+func TestWithAccept(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(recordHeaders(&headers, `{"items":[]}`))
+	defer server.Close()
+
+	client, err := lib.NewClient(lib.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Accept"); got != "application/vnd.github+json" {
+		t.Errorf("default Accept = %q, want application/vnd.github+json", got)
+	}
+
+	client, err = lib.NewClient(lib.WithBaseURL(server.URL), lib.WithAccept("application/vnd.github.v3.text-match+json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Accept"); got != "application/vnd.github.v3.text-match+json" {
+		t.Errorf("Accept = %q, want the WithAccept media type", got)
+	}
+	if _, err := client.GetReadmeHTML(lib.Item{FullName: "ryo-ma/lazyhub"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := headers.Get("Accept"); got != "application/vnd.github.html+json" {
+		t.Errorf("GetReadmeHTML Accept = %q, want its own media type over the default", got)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	var queries []string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Application Error", http.StatusServiceUnavailable)
+		}),
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query().Get("q"))
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	now := time.Date(2020, 6, 10, 12, 0, 0, 0, time.UTC)
+	if err := lib.WithClock(func() time.Time { return now })(client); err != nil {
+		t.Fatal(err)
+	}
+	client.TrendingFallback = true
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetTrendingRepository("go", "weekly"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"language:go created:>2020-05-11 fork:false archived:false",
+		"language:go created:>2020-05-11 fork:false archived:false",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+	if _, err := lib.NewClient(lib.WithClock(nil)); err == nil {
+		t.Error("WithClock(nil): want an error")
+	}
+}
+
+func TestWithPreview(t *testing.T) {
+	var searchHeaders, topicHeaders http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories":         recordHeaders(&searchHeaders, `{"items":[]}`),
+		"/repos/ryo-ma/lazyhub/topics": recordHeaders(&topicHeaders, `{"names":[]}`),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetRepositoryTopics("ryo-ma", "lazyhub"); err != nil {
+		t.Fatal(err)
+	}
+	for name, headers := range map[string]http.Header{"search": searchHeaders, "topics": topicHeaders} {
+		if got := headers.Get("Accept"); strings.Contains(got, "preview") {
+			t.Errorf("%s Accept = %q, want no preview by default", name, got)
+		}
+	}
+
+	if err := lib.WithPreview("mercy")(client); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := searchHeaders.Get("Accept"), "application/vnd.github.mercy-preview+json, application/vnd.github+json"; got != want {
+		t.Errorf("Accept = %q, want %q", got, want)
+	}
+	if _, err := lib.NewClient(lib.WithPreview("")); err == nil {
+		t.Error("WithPreview(\"\"): want an error")
+	}
+}
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type normalizedItem struct {
+	Name        string     `json:"name"`
+	URL         string     `json:"url"`
+	CloneURL    string     `json:"clone_url"`
+	Stars       int        `json:"stars"`
+	Description string     `json:"description"`
+	Language    string     `json:"language"`
+	Topics      []string   `json:"topics,omitempty"`
+	DataSource  DataSource `json:"data_source"`
+}
+
+func (item *Item) normalize() normalizedItem {
+	return normalizedItem{
+		Name:        item.GetRepositoryName(),
+		URL:         item.GetRepositoryURL(),
+		CloneURL:    item.GetCloneURL(),
+		Stars:       item.GetStars(),
+		Description: item.GetDescription(),
+		Language:    item.GetLanguage(),
+		Topics:      item.Topics,
+		DataSource:  item.DataSource,
+	}
+}
+
+func (result *Result) WriteJSON(writer io.Writer) error {
+	items := make([]normalizedItem, 0, len(result.Items))
+	for i := range result.Items {
+		items = append(items, result.Items[i].normalize())
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+var markdownCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ", "\r", " ")
+
+func (result *Result) WriteMarkdown(writer io.Writer) error {
+	if _, err := fmt.Fprintln(writer, "| Name | Stars | Language | Description |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(writer, "| --- | ---: | --- | --- |"); err != nil {
+		return err
+	}
+	for i := range result.Items {
+		item := result.Items[i].normalize()
+		_, err := fmt.Fprintf(writer, "| [%s](%s) | %d | %s | %s |\n",
+			markdownCellReplacer.Replace(item.Name),
+			item.URL,
+			item.Stars,
+			markdownCellReplacer.Replace(item.Language),
+			markdownCellReplacer.Replace(item.Description),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (result *Result) WriteCSV(writer io.Writer) error {
+	w := csv.NewWriter(writer)
+	if err := w.Write([]string{"name", "url", "stars", "language", "description"}); err != nil {
+		return err
+	}
+	for i := range result.Items {
+		item := result.Items[i].normalize()
+		record := []string{item.Name, item.URL, strconv.Itoa(item.Stars), item.Language, item.Description}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func (result *Result) WriteNDJSON(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	for i := range result.Items {
+		if err := encoder.Encode(result.Items[i].normalize()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
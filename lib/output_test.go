@@ -0,0 +1,108 @@
+package lib_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func sampleResult() *lib.Result {
+	return &lib.Result{Items: []lib.Item{
+		{
+			FullName:        "ryo-ma/lazyhub",
+			HTMLURL:         "https://github.com/ryo-ma/lazyhub",
+			CloneURL:        "https://github.com/ryo-ma/lazyhub.git",
+			Description:     "Terminal UI | for GitHub",
+			StargazersCount: 1200,
+			Language:        "Go",
+			Topics:          []string{"cli", "tui"},
+			DataSource:      lib.SourceOfficial,
+		},
+		{
+			Name:       "jroimartin / gocui",
+			URL:        "https://github.com/jroimartin/gocui",
+			Desc:       "Console \"UI\",\nin Go",
+			Stars:      "8,000",
+			Lang:       "Go",
+			DataSource: lib.SourceTrending,
+		},
+	}}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleResult().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "result.json", buf.Bytes())
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleResult().WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "result.md", buf.Bytes())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleResult().WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"name", "url", "stars", "language", "description"},
+		{"ryo-ma/lazyhub", "https://github.com/ryo-ma/lazyhub", "1200", "Go", "Terminal UI | for GitHub"},
+		{"jroimartin/gocui", "https://github.com/jroimartin/gocui", "8000", "Go", "Console \"UI\",\nin Go"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	result := sampleResult()
+	var buf bytes.Buffer
+	if err := result.WriteNDJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(result.Items) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(result.Items))
+	}
+	for i, line := range lines {
+		var item struct {
+			Name  string `json:"name"`
+			Stars int    `json:"stars"`
+		}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("line %d %q: %v", i, line, err)
+		}
+		if item.Name != result.Items[i].GetRepositoryName() || item.Stars != result.Items[i].GetStars() {
+			t.Errorf("line %d = %+v, want the normalized item", i, item)
+		}
+	}
+}
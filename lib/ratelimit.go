@@ -0,0 +1,53 @@
+package lib
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+func parseRateLimit(header http.Header) (*RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return nil, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return nil, false
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}, true
+}
+
+func (client *Client) LastRateLimit() *RateLimit {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.lastRateLimit == nil {
+		return nil
+	}
+	rateLimit := *client.lastRateLimit
+	return &rateLimit
+}
+
+func (client *Client) recordRateLimit(header http.Header) {
+	rateLimit, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	client.mu.Lock()
+	client.lastRateLimit = rateLimit
+	client.mu.Unlock()
+}
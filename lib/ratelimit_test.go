@@ -0,0 +1,42 @@
+package lib_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLastRateLimit(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "60")
+			w.Header().Set("X-RateLimit-Remaining", "59")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"items":[]}`))
+		}),
+	})
+	defer server.Close()
+
+	if rateLimit := client.LastRateLimit(); rateLimit != nil {
+		t.Errorf("LastRateLimit before any request = %+v, want nil", rateLimit)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Fatal(err)
+	}
+	rateLimit := client.LastRateLimit()
+	if rateLimit == nil {
+		t.Fatal("LastRateLimit = nil after a response with rate limit headers")
+	}
+	if rateLimit.Limit != 60 || rateLimit.Remaining != 59 || !rateLimit.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("LastRateLimit = %+v", rateLimit)
+	}
+
+	// Responses without the headers, like trending, keep the last value.
+	if _, err := client.GetTrendingRepository("", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.LastRateLimit(); got == nil || got.Remaining != 59 {
+		t.Errorf("LastRateLimit after trending = %+v, want Remaining 59", got)
+	}
+}
@@ -0,0 +1,277 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const htmlMediaType = "application/vnd.github.html+json"
+
+var (
+	markdownHeading    = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownFence      = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	markdownRule       = regexp.MustCompile(`^\s{0,3}([-*_])(\s*([-*_]))*\s*$`)
+	markdownSetext     = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	markdownQuote      = regexp.MustCompile(`^\s{0,3}>\s?`)
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownRefLink    = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	markdownRefDef     = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s+\S+`)
+	markdownHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	markdownEmphasis   = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	markdownItalic     = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*?)[*_]([^\w*]|$)`)
+	markdownInlineCode = regexp.MustCompile("`([^`]*)`")
+)
+
+var (
+	markdownURL = regexp.MustCompile(`(\]\()(<?)([^)\s>]+)`)
+	htmlURL     = regexp.MustCompile(`((?:src|href)\s*=\s*["'])([^"']+)`)
+)
+
+func (readme *Readme) rewriteRelativeURLs(fullName string, ref string) error {
+	content, err := readme.DecodedContent()
+	if err != nil {
+		return err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	root := "https://raw.githubusercontent.com/" + fullName + "/" + ref + "/"
+	dir := path.Dir(readme.Path)
+	if dir == "." {
+		dir = ""
+	}
+	absolute := func(link string) string {
+		u, err := url.Parse(link)
+		if err != nil || u.IsAbs() || u.Host != "" || strings.HasPrefix(link, "#") {
+			return link
+		}
+		if strings.HasPrefix(link, "/") {
+			return root + strings.TrimPrefix(link, "/")
+		}
+		return root + strings.TrimPrefix(path.Join(dir, link), "/")
+	}
+	content = markdownURL.ReplaceAllStringFunc(content, func(match string) string {
+		m := markdownURL.FindStringSubmatch(match)
+		return m[1] + m[2] + absolute(m[3])
+	})
+	content = htmlURL.ReplaceAllStringFunc(content, func(match string) string {
+		m := htmlURL.FindStringSubmatch(match)
+		return m[1] + absolute(m[2])
+	})
+	readme.Content = content
+	readme.Encoding = "none"
+	return nil
+}
+
+func (readme *Readme) PlainText() (string, error) {
+	content, err := readme.DecodedContent()
+	if err != nil {
+		return "", err
+	}
+	return MarkdownToText(content), nil
+}
+
+func MarkdownToText(markdown string) string {
+	lines := strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n")
+	text := make([]string, 0, len(lines))
+	inFence := false
+	for _, line := range lines {
+		if markdownFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			text = append(text, "    "+line)
+			continue
+		}
+		if markdownRefDef.MatchString(line) {
+			continue
+		}
+		if markdownSetext.MatchString(line) && len(text) != 0 && strings.TrimSpace(text[len(text)-1]) != "" {
+			continue
+		}
+		if markdownRule.MatchString(line) {
+			text = append(text, "")
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			line = m[2]
+		}
+		line = markdownQuote.ReplaceAllString(line, "")
+		text = append(text, markdownInlineText(line))
+	}
+	return strings.TrimSpace(strings.Join(text, "\n")) + "\n"
+}
+
+func markdownInlineText(line string) string {
+	line = markdownImage.ReplaceAllString(line, "$1")
+	line = markdownLink.ReplaceAllString(line, "$1")
+	line = markdownRefLink.ReplaceAllString(line, "$1")
+	line = markdownHTMLTag.ReplaceAllString(line, "")
+	line = markdownInlineCode.ReplaceAllString(line, "$1")
+	line = markdownEmphasis.ReplaceAllString(line, "$2")
+	line = markdownItalic.ReplaceAllString(line, "$1$2$3")
+	return line
+}
+
+type Heading struct {
+	Level  int
+	Title  string
+	Anchor string
+}
+
+func (readme *Readme) TableOfContents() ([]Heading, error) {
+	content, err := readme.DecodedContent()
+	if err != nil {
+		return nil, err
+	}
+	return MarkdownTableOfContents(content), nil
+}
+
+// MarkdownTableOfContents lists the ATX and setext headings outside code
+// fences, with anchors generated the way GitHub renders them.
+func MarkdownTableOfContents(markdown string) []Heading {
+	headings := []Heading{}
+	anchors := map[string]int{}
+	add := func(level int, title string) {
+		title = strings.TrimSpace(markdownInlineText(title))
+		if title == "" {
+			return
+		}
+		anchor := githubAnchor(title)
+		if n := anchors[anchor]; n > 0 {
+			anchors[anchor] = n + 1
+			anchor += "-" + strconv.Itoa(n)
+		} else {
+			anchors[anchor] = 1
+		}
+		headings = append(headings, Heading{Level: level, Title: title, Anchor: anchor})
+	}
+	inFence := false
+	previous := ""
+	for _, line := range strings.Split(strings.Replace(markdown, "\r\n", "\n", -1), "\n") {
+		if markdownFence.MatchString(line) {
+			inFence = !inFence
+			previous = ""
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			add(len(m[1]), m[2])
+			previous = ""
+			continue
+		}
+		if m := markdownSetext.FindStringSubmatch(line); m != nil && previous != "" {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			add(level, previous)
+			previous = ""
+			continue
+		}
+		previous = strings.TrimSpace(line)
+	}
+	return headings
+}
+
+// githubAnchor lowercases title, drops punctuation and turns spaces into
+// hyphens, matching the ids GitHub gives rendered headings.
+func githubAnchor(title string) string {
+	var anchor strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			anchor.WriteRune(r)
+		case r == ' ':
+			anchor.WriteRune('-')
+		}
+	}
+	return anchor.String()
+}
+
+func (client *Client) GetReadmeHTML(item Item) (string, error) {
+	return client.GetReadmeHTMLContext(context.Background(), item)
+}
+
+func (client *Client) GetReadmeHTMLContext(ctx context.Context, item Item) (string, error) {
+	url := client.apiURL("repos", item.GetOwner(), item.GetRepo(), "readme")
+	req, err := client.newRequestAccept(ctx, url.String(), htmlMediaType)
+	if err != nil {
+		return "", err
+	}
+	_, body, err := client.do(req, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+var readmeFallbackPaths = []string{"README.md", "readme.md", "docs/README.md"}
+
+// GetReadmeForItem is GetReadme, but when GitHub reports no README it tries
+// readmeFallbackPaths through the contents API. It returns the path of the
+// file that was found.
+func (client *Client) GetReadmeForItem(item Item) (*Readme, string, error) {
+	return client.GetReadmeForItemContext(context.Background(), item)
+}
+
+func (client *Client) GetReadmeForItemContext(ctx context.Context, item Item) (*Readme, string, error) {
+	readme, err := client.GetReadmeContext(ctx, item)
+	if err == nil {
+		return readme, readme.Path, nil
+	}
+	if !IsNotFound(err) {
+		return nil, "", err
+	}
+	for _, readmePath := range readmeFallbackPaths {
+		url := client.apiURL("repos", item.GetOwner(), item.GetRepo(), "contents", readmePath)
+		var fallback *Readme
+		_, fallbackErr := client.getJSON(ctx, url.String(), &fallback)
+		if IsNotFound(fallbackErr) {
+			continue
+		}
+		if fallbackErr != nil {
+			return nil, "", fallbackErr
+		}
+		if fallback == nil {
+			return nil, "", errEmptyResponse
+		}
+		return fallback, readmePath, nil
+	}
+	return nil, "", err
+}
+
+// GetReadmeRaw fetches the README's download_url, returning its bytes as
+// stored in the repository without the base64 round trip.
+func (client *Client) GetReadmeRaw(item Item) ([]byte, error) {
+	return client.GetReadmeRawContext(context.Background(), item)
+}
+
+func (client *Client) GetReadmeRawContext(ctx context.Context, item Item) ([]byte, error) {
+	readme, err := client.GetReadmeContext(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	if readme.DownloadURL == "" {
+		return nil, errors.New("readme has no download url")
+	}
+	req, err := client.newRequestAccept(ctx, readme.DownloadURL, "*/*")
+	if err != nil {
+		return nil, err
+	}
+	_, body, err := client.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,254 @@
+package lib_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestGetReadmeForItemFallsBack(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/owner/repo/readme":                  http.NotFoundHandler(),
+		"/repos/owner/missing/readme":               http.NotFoundHandler(),
+		"/repos/owner/repo/contents/docs/README.md": jsonBody(`{"name":"README.md","path":"docs/README.md","encoding":"base64","content":"IyBEb2Nz"}`),
+	})
+	defer server.Close()
+
+	readme, path, err := client.GetReadmeForItem(lib.Item{FullName: "owner/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "docs/README.md" {
+		t.Errorf("path = %q, want docs/README.md", path)
+	}
+	if content, _ := readme.DecodedContent(); content != "# Docs" {
+		t.Errorf("content = %q, want %q", content, "# Docs")
+	}
+
+	if _, _, err := client.GetReadmeForItem(lib.Item{FullName: "owner/missing"}); !lib.IsNotFound(err) {
+		t.Errorf("err = %v, want not found when every path 404s", err)
+	}
+}
+
+func TestNullBodiesAreErrors(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/owner/repo/readme": jsonBody("null"),
+		"/repos/owner/repo":        jsonBody("null"),
+		"/search/repositories":     jsonBody("null"),
+	})
+	defer server.Close()
+
+	if _, _, err := client.GetReadmeForItem(lib.Item{FullName: "owner/repo"}); err == nil {
+		t.Error("GetReadmeForItem: want an error for a null body")
+	}
+	if _, err := client.GetRepository("owner", "repo"); err == nil {
+		t.Error("GetRepository: want an error for a null body")
+	}
+	if _, err := client.SearchRepository("go"); err == nil {
+		t.Error("SearchRepository: want an error for a null body")
+	}
+}
+
+func TestGetReadmeDecodesBase64(t *testing.T) {
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+
+	readme, err := client.GetReadme(lib.Item{FullName: "ryo-ma/lazyhub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := readme.DecodedContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != lazyhubtest.Readme {
+		t.Errorf("content = %q, want %q", content, lazyhubtest.Readme)
+	}
+}
+
+func TestReadmeDecodedContent(t *testing.T) {
+	tests := []struct {
+		readme  lib.Readme
+		want    string
+		wantErr bool
+	}{
+		// GitHub wraps base64 content at 60 columns.
+		{lib.Readme{Encoding: "base64", Content: "IyBsYXp5\naHViCg==\n"}, "# lazyhub\n", false},
+		{lib.Readme{Encoding: "", Content: "plain"}, "plain", false},
+		{lib.Readme{Encoding: "none", Content: "plain"}, "plain", false},
+		{lib.Readme{Encoding: "base64", Content: "!!!"}, "", true},
+		{lib.Readme{Encoding: "utf-16", Content: "x"}, "", true},
+	}
+	for _, test := range tests {
+		got, err := test.readme.DecodedContent()
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("DecodedContent(%+v) = %q, %v; want %q, error %v", test.readme, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestGetReadmeWithRef(t *testing.T) {
+	var queries []url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/readme": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			jsonBody(`{"path":"README.md","encoding":"none","content":"# lazyhub"}`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	item := lib.Item{FullName: "ryo-ma/lazyhub"}
+
+	if _, err := client.GetReadmeWithOptions(item, lib.ReadmeOptions{Ref: "v1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetReadme(item); err != nil {
+		t.Fatal(err)
+	}
+	if got := queries[0].Get("ref"); got != "v1.0.0" {
+		t.Errorf("ref = %q, want v1.0.0", got)
+	}
+	if _, ok := queries[1]["ref"]; ok {
+		t.Errorf("GetReadme sent ref %q, want none", queries[1].Get("ref"))
+	}
+}
+
+func TestMarkdownToText(t *testing.T) {
+	markdown := "# lazyhub\n\nA **terminal** UI for [GitHub](https://github.com) with `go`.\n\n" +
+		"![logo](logo.png)\n\nUsage\n-----\n\n```sh\ngo get github.com/ryo-ma/lazyhub\n```\n\n" +
+		"> Note: _alpha_ software\n\n---\n\n[docs]: https://example.com\n"
+	want := "lazyhub\n\nA terminal UI for GitHub with go.\n\nlogo\n\nUsage\n\n" +
+		"    go get github.com/ryo-ma/lazyhub\n\nNote: alpha software\n"
+	if got := lib.MarkdownToText(markdown); got != want {
+		t.Errorf("MarkdownToText = %q, want %q", got, want)
+	}
+
+	readme := &lib.Readme{Encoding: "none", Content: markdown}
+	text, err := readme.PlainText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != want {
+		t.Errorf("PlainText = %q, want %q", text, want)
+	}
+	if readme.Content != markdown {
+		t.Error("PlainText modified the raw content")
+	}
+}
+
+func TestGetReadmeHTML(t *testing.T) {
+	const html = `<div id="readme"><h1>lazyhub</h1></div>`
+	var headers http.Header
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/readme": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			w.Header().Set("Content-Type", "application/vnd.github.html; charset=utf-8")
+			w.Write([]byte(html))
+		}),
+	})
+	defer server.Close()
+
+	got, err := client.GetReadmeHTML(lib.Item{FullName: "ryo-ma/lazyhub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != html {
+		t.Errorf("GetReadmeHTML = %q, want %q", got, html)
+	}
+	if accept := headers.Get("Accept"); accept != "application/vnd.github.html+json" {
+		t.Errorf("Accept = %q, want the HTML media type", accept)
+	}
+}
+
+func TestGetReadmeAbsoluteURLs(t *testing.T) {
+	markdown := "![logo](images/logo.png)\n" +
+		"[guide](/docs/guide.md) and [site](https://example.com) and [top](#usage)\n" +
+		`<img src="./shot.png" alt="shot">` + "\n"
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/readme": jsonBody(`{"path":"docs/README.md","encoding":"base64","content":"` +
+			base64.StdEncoding.EncodeToString([]byte(markdown)) + `"}`),
+	})
+	defer server.Close()
+	item := lib.Item{FullName: "ryo-ma/lazyhub", DefaultBranch: "master"}
+
+	readme, err := client.GetReadmeWithOptions(item, lib.ReadmeOptions{AbsoluteURLs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := readme.DecodedContent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const root = "https://raw.githubusercontent.com/ryo-ma/lazyhub/master/"
+	want := "![logo](" + root + "docs/images/logo.png)\n" +
+		"[guide](" + root + "docs/guide.md) and [site](https://example.com) and [top](#usage)\n" +
+		`<img src="` + root + `docs/shot.png" alt="shot">` + "\n"
+	if content != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+
+	readme, err = client.GetReadmeWithOptions(item, lib.ReadmeOptions{AbsoluteURLs: true, Ref: "v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := readme.DecodedContent(); !strings.Contains(content, "/ryo-ma/lazyhub/v1.0.0/docs/images/logo.png") {
+		t.Errorf("content = %q, want URLs at the ref", content)
+	}
+}
+
+func TestGetReadmeRaw(t *testing.T) {
+	raw := []byte("# lazyhub\n\x00binary-safe\xff\n")
+	var accept string
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	server.Handle("/repos/ryo-ma/lazyhub/readme", jsonBody(`{"path":"README.md","download_url":"`+server.URL+`/raw/ryo-ma/lazyhub/master/README.md"}`))
+	server.Handle("/raw/ryo-ma/lazyhub/master/README.md", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(raw)
+	}))
+
+	got, err := client.GetReadmeRaw(lib.Item{FullName: "ryo-ma/lazyhub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, raw) {
+		t.Errorf("GetReadmeRaw = %q, want %q", got, raw)
+	}
+	if accept != "*/*" {
+		t.Errorf("Accept = %q, want */*", accept)
+	}
+
+	server.Handle("/repos/owner/repo/readme", jsonBody(`{"path":"README.md"}`))
+	if _, err := client.GetReadmeRaw(lib.Item{FullName: "owner/repo"}); err == nil {
+		t.Error("missing download_url: want an error")
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	markdown := "# LazyHub\n\nIntro\n\n## Getting Started!\n\n### Install (macOS & Linux)\n\n" +
+		"```\n# not a heading\n```\n\n## Usage\n\nSetext Title\n------------\n\n## Usage\n\n#### `lib.Client` API\n"
+	readme := &lib.Readme{Encoding: "base64", Content: base64.StdEncoding.EncodeToString([]byte(markdown))}
+	headings, err := readme.TableOfContents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lib.Heading{
+		{Level: 1, Title: "LazyHub", Anchor: "lazyhub"},
+		{Level: 2, Title: "Getting Started!", Anchor: "getting-started"},
+		{Level: 3, Title: "Install (macOS & Linux)", Anchor: "install-macos--linux"},
+		{Level: 2, Title: "Usage", Anchor: "usage"},
+		{Level: 2, Title: "Setext Title", Anchor: "setext-title"},
+		{Level: 2, Title: "Usage", Anchor: "usage-1"},
+		{Level: 4, Title: "lib.Client API", Anchor: "libclient-api"},
+	}
+	if !reflect.DeepEqual(headings, want) {
+		t.Errorf("headings = %+v, want %+v", headings, want)
+	}
+}
@@ -0,0 +1,452 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const starMediaType = "application/vnd.github.star+json"
+
+type ListOptions struct {
+	Page    int
+	PerPage int
+}
+
+func (opts ListOptions) encode(q url.Values) {
+	if opts.Page > 0 {
+		q.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage > 0 {
+		q.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+}
+
+type Contributor struct {
+	Login         string `json:"login"`
+	Contributions int    `json:"contributions"`
+	AvatarURL     string `json:"avatar_url"`
+	HTMLURL       string `json:"html_url"`
+}
+
+func (client *Client) GetContributors(owner string, name string, opts ListOptions) ([]Contributor, error) {
+	return client.GetContributorsContext(context.Background(), owner, name, opts)
+}
+
+func (client *Client) GetContributorsContext(ctx context.Context, owner string, name string, opts ListOptions) ([]Contributor, error) {
+	url := client.apiURL("repos", owner, name, "contributors")
+	q := url.Query()
+	opts.encode(q)
+	url.RawQuery = q.Encode()
+	var contributors []Contributor
+	if _, err := client.getJSON(ctx, url.String(), &contributors); err != nil {
+		return nil, err
+	}
+	return contributors, nil
+}
+
+type Release struct {
+	TagName     string         `json:"tag_name"`
+	Name        string         `json:"name"`
+	HTMLURL     string         `json:"html_url"`
+	Draft       bool           `json:"draft"`
+	Prerelease  bool           `json:"prerelease"`
+	PublishedAt time.Time      `json:"published_at"`
+	Assets      []ReleaseAsset `json:"assets"`
+}
+
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	Size               int    `json:"size"`
+	ContentType        string `json:"content_type"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func (client *Client) GetReleases(owner string, name string, opts ListOptions) ([]Release, error) {
+	return client.GetReleasesContext(context.Background(), owner, name, opts)
+}
+
+func (client *Client) GetReleasesContext(ctx context.Context, owner string, name string, opts ListOptions) ([]Release, error) {
+	url := client.apiURL("repos", owner, name, "releases")
+	q := url.Query()
+	opts.encode(q)
+	url.RawQuery = q.Encode()
+	var releases []Release
+	if _, err := client.getJSON(ctx, url.String(), &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+func (client *Client) GetLatestRelease(owner string, name string) (*Release, error) {
+	return client.GetLatestReleaseContext(context.Background(), owner, name)
+}
+
+func (client *Client) GetLatestReleaseContext(ctx context.Context, owner string, name string) (*Release, error) {
+	url := client.apiURL("repos", owner, name, "releases", "latest")
+	var release *Release
+	if _, err := client.getJSON(ctx, url.String(), &release); err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, errEmptyResponse
+	}
+	return release, nil
+}
+
+func (client *Client) getRepositoryList(ctx context.Context, url string, opts ListOptions) (*Result, error) {
+	var items []Item
+	resp, err := client.getJSON(ctx, url, &items)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].DataSource = SourceOfficial
+	}
+	return &Result{
+		Items:   items,
+		Page:    opts.Page,
+		PerPage: opts.PerPage,
+		Links:   ParseLinkHeader(resp.Header.Get("Link")),
+	}, nil
+}
+
+func (client *Client) GetStarredRepositories(username string, opts ListOptions) (*Result, error) {
+	return client.GetStarredRepositoriesContext(context.Background(), username, opts)
+}
+
+func (client *Client) GetStarredRepositoriesContext(ctx context.Context, username string, opts ListOptions) (*Result, error) {
+	url := client.apiURL("users", username, "starred")
+	q := url.Query()
+	opts.encode(q)
+	url.RawQuery = q.Encode()
+	return client.getRepositoryList(ctx, url.String(), opts)
+}
+
+type UserRepositoryOptions struct {
+	ListOptions
+	Type      string
+	Sort      string
+	Direction string
+}
+
+func (opts UserRepositoryOptions) encode(q url.Values) error {
+	opts.ListOptions.encode(q)
+	switch opts.Type {
+	case "":
+	case "all", "owner", "member":
+		q.Set("type", opts.Type)
+	default:
+		return fmt.Errorf("invalid repository type %q: must be all, owner or member", opts.Type)
+	}
+	switch opts.Sort {
+	case "":
+	case "created", "updated", "pushed", "full_name":
+		q.Set("sort", opts.Sort)
+	default:
+		return fmt.Errorf("invalid repository sort %q: must be created, updated, pushed or full_name", opts.Sort)
+	}
+	switch opts.Direction {
+	case "":
+	case "asc", "desc":
+		q.Set("direction", opts.Direction)
+	default:
+		return fmt.Errorf("invalid direction %q: must be asc or desc", opts.Direction)
+	}
+	return nil
+}
+
+func (client *Client) GetUserRepositories(username string, opts UserRepositoryOptions) (*Result, error) {
+	return client.GetUserRepositoriesContext(context.Background(), username, opts)
+}
+
+func (client *Client) GetUserRepositoriesContext(ctx context.Context, username string, opts UserRepositoryOptions) (*Result, error) {
+	url := client.apiURL("users", username, "repos")
+	q := url.Query()
+	if err := opts.encode(q); err != nil {
+		return nil, err
+	}
+	url.RawQuery = q.Encode()
+	return client.getRepositoryList(ctx, url.String(), opts.ListOptions)
+}
+
+func (client *Client) GetRepositoryLanguages(owner string, name string) (map[string]int, error) {
+	return client.GetRepositoryLanguagesContext(context.Background(), owner, name)
+}
+
+func (client *Client) GetRepositoryLanguagesContext(ctx context.Context, owner string, name string) (map[string]int, error) {
+	url := client.apiURL("repos", owner, name, "languages")
+	languages := map[string]int{}
+	if _, err := client.getJSON(ctx, url.String(), &languages); err != nil {
+		return nil, err
+	}
+	return languages, nil
+}
+
+func LanguagePercentages(languages map[string]int) map[string]float64 {
+	total := 0
+	for _, bytes := range languages {
+		total += bytes
+	}
+	percentages := make(map[string]float64, len(languages))
+	if total == 0 {
+		return percentages
+	}
+	for language, bytes := range languages {
+		percentages[language] = float64(bytes) * 100 / float64(total)
+	}
+	return percentages
+}
+
+type ForkOptions struct {
+	ListOptions
+	Sort string
+}
+
+func (client *Client) GetForks(owner string, name string, opts ForkOptions) (*Result, error) {
+	return client.GetForksContext(context.Background(), owner, name, opts)
+}
+
+func (client *Client) GetForksContext(ctx context.Context, owner string, name string, opts ForkOptions) (*Result, error) {
+	url := client.apiURL("repos", owner, name, "forks")
+	q := url.Query()
+	opts.encode(q)
+	switch opts.Sort {
+	case "":
+	case "newest", "oldest", "stargazers", "watchers":
+		q.Set("sort", opts.Sort)
+	default:
+		return nil, fmt.Errorf("invalid fork sort %q: must be newest, oldest, stargazers or watchers", opts.Sort)
+	}
+	url.RawQuery = q.Encode()
+	return client.getRepositoryList(ctx, url.String(), opts.ListOptions)
+}
+
+type Stargazer struct {
+	StarredAt time.Time `json:"starred_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+type StarBucket struct {
+	Start time.Time
+	Count int
+}
+
+// GetStargazers lists one page of stargazers with their starred_at
+// timestamps. GitHub orders them oldest first, so the newest stars are on the
+// last page.
+func (client *Client) GetStargazers(owner string, name string, opts ListOptions) ([]Stargazer, error) {
+	return client.GetStargazersContext(context.Background(), owner, name, opts)
+}
+
+func (client *Client) GetStargazersContext(ctx context.Context, owner string, name string, opts ListOptions) ([]Stargazer, error) {
+	url := client.apiURL("repos", owner, name, "stargazers")
+	q := url.Query()
+	opts.encode(q)
+	url.RawQuery = q.Encode()
+	stargazers, _, err := client.getStargazerPage(ctx, url.String())
+	return stargazers, err
+}
+
+func (client *Client) getStargazerPage(ctx context.Context, url string) ([]Stargazer, Links, error) {
+	req, err := client.newRequestAccept(ctx, url, starMediaType)
+	if err != nil {
+		return nil, Links{}, err
+	}
+	var stargazers []Stargazer
+	resp, _, err := client.do(req, jsonDecoder(&stargazers))
+	if err != nil {
+		return nil, Links{}, err
+	}
+	return stargazers, ParseLinkHeader(resp.Header.Get("Link")), nil
+}
+
+const stargazerHistoryPerPage = 100
+
+func (client *Client) GetStargazerHistory(owner string, name string, interval time.Duration, since time.Time) ([]StarBucket, error) {
+	return client.GetStargazerHistoryContext(context.Background(), owner, name, interval, since)
+}
+
+// GetStargazerHistoryContext buckets the stars given since the given time.
+// Because stargazers are listed oldest first, it jumps to the last page and
+// follows rel="prev" links until a page starts before since. A zero since
+// walks the whole history.
+func (client *Client) GetStargazerHistoryContext(ctx context.Context, owner string, name string, interval time.Duration, since time.Time) ([]StarBucket, error) {
+	url := client.apiURL("repos", owner, name, "stargazers")
+	q := url.Query()
+	ListOptions{PerPage: stargazerHistoryPerPage}.encode(q)
+	url.RawQuery = q.Encode()
+	page, links, err := client.getStargazerPage(ctx, url.String())
+	if err != nil {
+		return nil, err
+	}
+	if links.Last != "" {
+		page, links, err = client.getStargazerPage(ctx, links.Last)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var recent []Stargazer
+	for {
+		for _, stargazer := range page {
+			if !stargazer.StarredAt.Before(since) {
+				recent = append(recent, stargazer)
+			}
+		}
+		if links.Prev == "" || (len(page) > 0 && page[0].StarredAt.Before(since)) {
+			break
+		}
+		page, links, err = client.getStargazerPage(ctx, links.Prev)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return BucketStargazers(recent, interval), nil
+}
+
+// BucketStargazers counts stars per interval, in chronological order.
+// Buckets are aligned to UTC multiples of interval.
+func BucketStargazers(stargazers []Stargazer, interval time.Duration) []StarBucket {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	counts := map[time.Time]int{}
+	for _, stargazer := range stargazers {
+		if stargazer.StarredAt.IsZero() {
+			continue
+		}
+		counts[stargazer.StarredAt.UTC().Truncate(interval)]++
+	}
+	buckets := make([]StarBucket, 0, len(counts))
+	for start, count := range counts {
+		buckets = append(buckets, StarBucket{Start: start, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets
+}
+
+type Issue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request,omitempty"`
+}
+
+func (issue Issue) IsPullRequest() bool {
+	return issue.PullRequest != nil
+}
+
+type IssueOptions struct {
+	ListOptions
+	State  string
+	Labels []string
+	// IncludePullRequests keeps pull requests, which the issues endpoint
+	// returns alongside issues.
+	IncludePullRequests bool
+}
+
+func (opts IssueOptions) encode(q url.Values) error {
+	opts.ListOptions.encode(q)
+	switch opts.State {
+	case "":
+	case "open", "closed", "all":
+		q.Set("state", opts.State)
+	default:
+		return fmt.Errorf("invalid issue state %q: must be open, closed or all", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		q.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	return nil
+}
+
+func (client *Client) GetIssues(owner string, name string, opts IssueOptions) ([]Issue, error) {
+	return client.GetIssuesContext(context.Background(), owner, name, opts)
+}
+
+func (client *Client) GetIssuesContext(ctx context.Context, owner string, name string, opts IssueOptions) ([]Issue, error) {
+	url := client.apiURL("repos", owner, name, "issues")
+	q := url.Query()
+	if err := opts.encode(q); err != nil {
+		return nil, err
+	}
+	url.RawQuery = q.Encode()
+	var issues []Issue
+	if _, err := client.getJSON(ctx, url.String(), &issues); err != nil {
+		return nil, err
+	}
+	if opts.IncludePullRequests {
+		return issues, nil
+	}
+	filtered := issues[:0]
+	for _, issue := range issues {
+		if !issue.IsPullRequest() {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered, nil
+}
+
+var ErrNoLicense = errors.New("no license detected")
+
+type License struct {
+	Key     string
+	Name    string
+	SPDXID  string
+	Path    string
+	HTMLURL string
+	Body    string
+}
+
+func (client *Client) GetLicense(owner string, name string) (*License, error) {
+	return client.GetLicenseContext(context.Background(), owner, name)
+}
+
+// GetLicenseContext returns a nil License and ErrNoLicense when GitHub does
+// not detect a license for the repository.
+func (client *Client) GetLicenseContext(ctx context.Context, owner string, name string) (*License, error) {
+	url := client.apiURL("repos", owner, name, "license")
+	var response struct {
+		Path     string `json:"path"`
+		HTMLURL  string `json:"html_url"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		License  struct {
+			Key    string `json:"key"`
+			Name   string `json:"name"`
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+	if _, err := client.getJSON(ctx, url.String(), &response); err != nil {
+		if IsNotFound(err) {
+			return nil, ErrNoLicense
+		}
+		return nil, err
+	}
+	body, err := (&Readme{Content: response.Content, Encoding: response.Encoding}).DecodedContent()
+	if err != nil {
+		return nil, err
+	}
+	return &License{
+		Key:     response.License.Key,
+		Name:    response.License.Name,
+		SPDXID:  response.License.SPDXID,
+		Path:    response.Path,
+		HTMLURL: response.HTMLURL,
+		Body:    body,
+	}, nil
+}
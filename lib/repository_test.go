@@ -0,0 +1,352 @@
+package lib_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestGetContributors(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/contributors": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`[
+				{"login":"ryo-ma","contributions":120,"avatar_url":"https://avatars.githubusercontent.com/u/1"},
+				{"login":"octocat","contributions":3,"avatar_url":"https://avatars.githubusercontent.com/u/2"},
+				{"login":"hubot","contributions":40,"avatar_url":"https://avatars.githubusercontent.com/u/3"}
+			]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	contributors, err := client.GetContributors("ryo-ma", "lazyhub", lib.ListOptions{Page: 2, PerPage: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("page") != "2" || query.Get("per_page") != "3" {
+		t.Errorf("query = %v, want page=2 and per_page=3", query)
+	}
+	logins := []string{}
+	for _, contributor := range contributors {
+		logins = append(logins, contributor.Login)
+	}
+	if want := []string{"ryo-ma", "octocat", "hubot"}; !reflect.DeepEqual(logins, want) {
+		t.Errorf("logins = %v, want %v in response order", logins, want)
+	}
+	if contributors[0].Contributions != 120 || contributors[0].AvatarURL != "https://avatars.githubusercontent.com/u/1" {
+		t.Errorf("contributors[0] = %+v", contributors[0])
+	}
+}
+
+func TestGetReleases(t *testing.T) {
+	const release = `{
+		"tag_name": "v1.2.0",
+		"name": "lazyhub 1.2.0",
+		"published_at": "2020-06-01T12:00:00Z",
+		"assets": [{
+			"name": "lazyhub_linux_amd64.tar.gz",
+			"size": 2048,
+			"browser_download_url": "https://github.com/ryo-ma/lazyhub/releases/download/v1.2.0/lazyhub_linux_amd64.tar.gz"
+		}]
+	}`
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/releases":        jsonBody(`[` + release + `, {"tag_name":"v1.1.0","prerelease":true}]`),
+		"/repos/ryo-ma/lazyhub/releases/latest": jsonBody(release),
+	})
+	defer server.Close()
+
+	releases, err := client.GetReleases("ryo-ma", "lazyhub", lib.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 2 || releases[0].TagName != "v1.2.0" || !releases[1].Prerelease {
+		t.Fatalf("releases = %+v", releases)
+	}
+	latest, err := client.GetLatestRelease("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*latest, releases[0]) {
+		t.Errorf("latest = %+v, want %+v", latest, releases[0])
+	}
+	if want := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC); !latest.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", latest.PublishedAt, want)
+	}
+	if len(latest.Assets) != 1 || latest.Assets[0].Size != 2048 || !strings.HasSuffix(latest.Assets[0].BrowserDownloadURL, "/lazyhub_linux_amd64.tar.gz") {
+		t.Errorf("assets = %+v", latest.Assets)
+	}
+}
+
+func TestGetStarredRepositories(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/users/octocat/starred": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			w.Header().Set("Link", `<`+"https://api.github.com/user/583231/starred?page=3"+`>; rel="next"`)
+			jsonBody(`[
+				{"full_name":"ryo-ma/lazyhub","stargazers_count":1200,"language":"Go"},
+				{"full_name":"jroimartin/gocui","stargazers_count":8000,"language":"Go"}
+			]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	result, err := client.GetStarredRepositories("octocat", lib.ListOptions{Page: 2, PerPage: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("page") != "2" || query.Get("per_page") != "2" {
+		t.Errorf("query = %v, want page=2 and per_page=2", query)
+	}
+	if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("items = %v, want %v", names(result.Items), want)
+	}
+	for _, item := range result.Items {
+		if item.DataSource != lib.SourceOfficial {
+			t.Errorf("%s DataSource = %q, want %q", item.GetRepositoryName(), item.DataSource, lib.SourceOfficial)
+		}
+	}
+	if !result.HasNextPage() {
+		t.Error("HasNextPage = false, want the Link header's next page")
+	}
+}
+
+func TestGetUserRepositories(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/users/octocat/repos": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`[{"full_name":"octocat/hello-world","stargazers_count":42,"description":"My first repository"}]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	result, err := client.GetUserRepositories("octocat", lib.UserRepositoryOptions{
+		ListOptions: lib.ListOptions{PerPage: 10},
+		Type:        "owner",
+		Sort:        "pushed",
+		Direction:   "asc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{"type": {"owner"}, "sort": {"pushed"}, "direction": {"asc"}, "per_page": {"10"}}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("query = %v, want %v", query, want)
+	}
+	if len(result.Items) != 1 || result.Items[0].GetStars() != 42 || result.Items[0].DataSource != lib.SourceOfficial {
+		t.Errorf("items = %+v", result.Items)
+	}
+
+	for _, opts := range []lib.UserRepositoryOptions{{Type: "forks"}, {Sort: "stars"}, {Direction: "up"}} {
+		if _, err := client.GetUserRepositories("octocat", opts); err == nil {
+			t.Errorf("GetUserRepositories with %+v: want an error", opts)
+		}
+	}
+}
+
+func TestGetRepositoryLanguages(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/languages": jsonBody(`{"Go":7500,"Shell":2000,"Makefile":500}`),
+	})
+	defer server.Close()
+
+	languages, err := client.GetRepositoryLanguages("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"Go": 7500, "Shell": 2000, "Makefile": 500}; !reflect.DeepEqual(languages, want) {
+		t.Errorf("languages = %v, want %v", languages, want)
+	}
+	if want := map[string]float64{"Go": 75, "Shell": 20, "Makefile": 5}; !reflect.DeepEqual(lib.LanguagePercentages(languages), want) {
+		t.Errorf("percentages = %v, want %v", lib.LanguagePercentages(languages), want)
+	}
+	if got := lib.LanguagePercentages(map[string]int{}); len(got) != 0 {
+		t.Errorf("percentages of nothing = %v, want empty", got)
+	}
+}
+
+func TestGetForks(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/jroimartin/gocui/forks": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`[
+				{"full_name":"awesome-gocui/gocui","stargazers_count":300},
+				{"full_name":"someone/gocui","stargazers_count":2}
+			]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	result, err := client.GetForks("jroimartin", "gocui", lib.ForkOptions{Sort: "stargazers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Get("sort"); got != "stargazers" {
+		t.Errorf("sort = %q, want stargazers", got)
+	}
+	if want := []string{"awesome-gocui/gocui", "someone/gocui"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("forks = %v, want %v", names(result.Items), want)
+	}
+	if _, err := client.GetForks("jroimartin", "gocui", lib.ForkOptions{Sort: "stars"}); err == nil {
+		t.Error("GetForks with an unknown sort: want an error")
+	}
+}
+
+func TestGetStargazerHistory(t *testing.T) {
+	var accept string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/stargazers": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			jsonBody(`[
+				{"starred_at":"2020-06-02T09:00:00Z","user":{"login":"c"}},
+				{"starred_at":"2020-06-01T01:00:00Z","user":{"login":"a"}},
+				{"starred_at":"2020-06-01T23:59:59Z","user":{"login":"b"}},
+				{"starred_at":"2020-06-04T00:00:00+09:00","user":{"login":"d"}}
+			]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	buckets, err := client.GetStargazerHistory("ryo-ma", "lazyhub", 24*time.Hour, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accept != "application/vnd.github.star+json" {
+		t.Errorf("Accept = %q, want the star media type", accept)
+	}
+	day := func(d int) time.Time { return time.Date(2020, 6, d, 0, 0, 0, 0, time.UTC) }
+	want := []lib.StarBucket{{Start: day(1), Count: 2}, {Start: day(2), Count: 1}, {Start: day(3), Count: 1}}
+	if !reflect.DeepEqual(buckets, want) {
+		t.Errorf("buckets = %v, want %v", buckets, want)
+	}
+}
+
+func TestGetStargazerHistoryWalksBackFromLastPage(t *testing.T) {
+	pages := map[string]string{
+		"1": `[{"starred_at":"2019-01-01T00:00:00Z"},{"starred_at":"2019-02-01T00:00:00Z"}]`,
+		"2": `[{"starred_at":"2020-05-30T00:00:00Z"},{"starred_at":"2020-06-01T10:00:00Z"}]`,
+		"3": `[{"starred_at":"2020-06-01T12:00:00Z"},{"starred_at":"2020-06-02T12:00:00Z"}]`,
+	}
+	var requested []string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/stargazers": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			requested = append(requested, page)
+			link := func(page, rel string) string {
+				return fmt.Sprintf(`<http://%s%s?page=%s&per_page=100>; rel="%s"`, r.Host, r.URL.Path, page, rel)
+			}
+			switch page {
+			case "1":
+				w.Header().Set("Link", link("2", "next")+", "+link("3", "last"))
+			case "2":
+				w.Header().Set("Link", link("1", "prev")+", "+link("3", "next"))
+			case "3":
+				w.Header().Set("Link", link("2", "prev")+", "+link("1", "first"))
+			}
+			jsonBody(pages[page]).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	since := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	buckets, err := client.GetStargazerHistory("ryo-ma", "lazyhub", 24*time.Hour, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "3", "2"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested pages %v, want %v", requested, want)
+	}
+	day := func(d int) time.Time { return time.Date(2020, 6, d, 0, 0, 0, 0, time.UTC) }
+	want := []lib.StarBucket{{Start: day(1), Count: 2}, {Start: day(2), Count: 1}}
+	if !reflect.DeepEqual(buckets, want) {
+		t.Errorf("buckets = %v, want %v", buckets, want)
+	}
+}
+
+func TestGetIssues(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/issues": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`[
+				{"number":3,"title":"Crash on start","state":"open","user":{"login":"octocat"},"created_at":"2020-06-01T00:00:00Z"},
+				{"number":2,"title":"Fix crash","state":"open","pull_request":{"url":"https://api.github.com/repos/ryo-ma/lazyhub/pulls/2"}},
+				{"number":1,"title":"Add docs","state":"open"}
+			]`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	issues, err := client.GetIssues("ryo-ma", "lazyhub", lib.IssueOptions{State: "all", Labels: []string{"bug", "help wanted"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("state") != "all" || query.Get("labels") != "bug,help wanted" {
+		t.Errorf("query = %v, want state=all and labels=bug,help wanted", query)
+	}
+	if len(issues) != 2 || issues[0].Number != 3 || issues[1].Number != 1 {
+		t.Fatalf("issues = %+v, want #3 and #1 without the pull request", issues)
+	}
+	if issues[0].User.Login != "octocat" || !issues[0].CreatedAt.Equal(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("issues[0] = %+v", issues[0])
+	}
+
+	issues, err = client.GetIssues("ryo-ma", "lazyhub", lib.IssueOptions{IncludePullRequests: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 || !issues[1].IsPullRequest() {
+		t.Errorf("issues = %+v, want all three with #2 as a pull request", issues)
+	}
+	if _, err := client.GetIssues("ryo-ma", "lazyhub", lib.IssueOptions{State: "merged"}); err == nil {
+		t.Error("GetIssues with an unknown state: want an error")
+	}
+}
+
+func TestGetLicense(t *testing.T) {
+	const text = "MIT License\n\nCopyright (c) 2020 ryo-ma\n"
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repos/ryo-ma/lazyhub/license": jsonBody(`{
+			"path": "LICENSE",
+			"html_url": "https://github.com/ryo-ma/lazyhub/blob/master/LICENSE",
+			"encoding": "base64",
+			"content": "` + base64.StdEncoding.EncodeToString([]byte(text)) + `",
+			"license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"}
+		}`),
+	})
+	defer server.Close()
+
+	license, err := client.GetLicense("ryo-ma", "lazyhub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &lib.License{
+		Key:     "mit",
+		Name:    "MIT License",
+		SPDXID:  "MIT",
+		Path:    "LICENSE",
+		HTMLURL: "https://github.com/ryo-ma/lazyhub/blob/master/LICENSE",
+		Body:    text,
+	}
+	if !reflect.DeepEqual(license, want) {
+		t.Errorf("license = %+v, want %+v", license, want)
+	}
+
+	license, err = client.GetLicense("ryo-ma", "unlicensed")
+	if license != nil || err != lib.ErrNoLicense {
+		t.Errorf("no license: GetLicense = %v, %v; want nil, ErrNoLicense", license, err)
+	}
+}
@@ -0,0 +1,221 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Equal reports whether item and other name the same repository, whatever
+// their DataSource or the formatting of their names.
+func (item *Item) Equal(other Item) bool {
+	return item.key() == other.key()
+}
+
+// EqualStars is Equal that also requires matching star counts.
+func (item *Item) EqualStars(other Item) bool {
+	return item.Equal(other) && item.GetStars() == other.GetStars()
+}
+
+func (item *Item) key() string {
+	return strings.ToLower(item.GetRepositoryName())
+}
+
+func (result *Result) Dedupe() {
+	seen := make(map[string]bool, len(result.Items))
+	items := result.Items[:0]
+	for _, item := range result.Items {
+		key := item.key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, item)
+	}
+	result.Items = items
+}
+
+func (result *Result) SortBy(field string, descending bool) error {
+	var less func(a, b *Item) bool
+	switch field {
+	case "stars":
+		less = func(a, b *Item) bool { return a.GetStars() < b.GetStars() }
+	case "name":
+		less = func(a, b *Item) bool {
+			return strings.ToLower(a.GetRepositoryName()) < strings.ToLower(b.GetRepositoryName())
+		}
+	case "updated":
+		less = func(a, b *Item) bool { return parseTime(a.UpdatedAt).Before(parseTime(b.UpdatedAt)) }
+	default:
+		return fmt.Errorf("invalid sort field %q: must be stars, name or updated", field)
+	}
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		if descending {
+			return less(&result.Items[j], &result.Items[i])
+		}
+		return less(&result.Items[i], &result.Items[j])
+	})
+	return nil
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func (result *Result) Filter(keep func(Item) bool) *Result {
+	filtered := *result
+	filtered.Items = []Item{}
+	for _, item := range result.Items {
+		if keep(item) {
+			filtered.Items = append(filtered.Items, item)
+		}
+	}
+	return &filtered
+}
+
+func (result *Result) Each(fn func(Item)) {
+	for _, item := range result.Items {
+		fn(item)
+	}
+}
+
+func (result *Result) FilterStars(min int) *Result {
+	return result.Filter(func(item Item) bool { return item.GetStars() >= min })
+}
+
+func (result *Result) FilterLanguage(langs ...string) *Result {
+	wanted := make(map[string]bool, len(langs))
+	for _, lang := range langs {
+		wanted[strings.ToLower(lang)] = true
+	}
+	return result.Filter(func(item Item) bool { return wanted[strings.ToLower(item.GetLanguage())] })
+}
+
+func (result *Result) Top(n int) *Result {
+	top := *result
+	top.Items = append([]Item{}, result.Items...)
+	sort.SliceStable(top.Items, func(i, j int) bool {
+		return top.Items[i].GetStars() > top.Items[j].GetStars()
+	})
+	if n >= 0 && n < len(top.Items) {
+		top.Items = top.Items[:n]
+	}
+	return &top
+}
+
+const NoLanguage = "(none)"
+
+func (result *Result) GroupByLanguage() map[string][]Item {
+	groups := map[string][]Item{}
+	for _, item := range result.Items {
+		language := item.GetLanguage()
+		if language == "" {
+			language = NoLanguage
+		}
+		groups[language] = append(groups[language], item)
+	}
+	return groups
+}
+
+type Diff struct {
+	Added        map[string]Item
+	Removed      map[string]Item
+	StarsChanged map[string]StarChange
+}
+
+type StarChange struct {
+	Item     Item
+	OldStars int
+	NewStars int
+}
+
+// ResultDiff compares two fetches of the same listing, keyed by repository
+// name. Either Result may be nil.
+func ResultDiff(oldResult *Result, newResult *Result) *Diff {
+	diff := &Diff{
+		Added:        map[string]Item{},
+		Removed:      map[string]Item{},
+		StarsChanged: map[string]StarChange{},
+	}
+	previous := map[string]Item{}
+	if oldResult != nil {
+		for _, item := range oldResult.Items {
+			previous[item.key()] = item
+		}
+	}
+	current := map[string]bool{}
+	if newResult != nil {
+		for _, item := range newResult.Items {
+			key := item.key()
+			current[key] = true
+			old, ok := previous[key]
+			if !ok {
+				diff.Added[item.GetRepositoryName()] = item
+				continue
+			}
+			if !old.EqualStars(item) {
+				diff.StarsChanged[item.GetRepositoryName()] = StarChange{Item: item, OldStars: old.GetStars(), NewStars: item.GetStars()}
+			}
+		}
+	}
+	for key, item := range previous {
+		if !current[key] {
+			diff.Removed[item.GetRepositoryName()] = item
+		}
+	}
+	return diff
+}
+
+type Stats struct {
+	Count        int
+	TotalStars   int
+	AverageStars float64
+	MedianStars  float64
+	Languages    map[string]int
+	// NewestUpdate and OldestUpdate are zero when no item has a valid
+	// UpdatedAt.
+	NewestUpdate time.Time
+	OldestUpdate time.Time
+}
+
+func (result *Result) Stats() Stats {
+	stats := Stats{Count: len(result.Items), Languages: map[string]int{}}
+	stars := make([]int, 0, len(result.Items))
+	for i := range result.Items {
+		item := &result.Items[i]
+		stars = append(stars, item.GetStars())
+		stats.TotalStars += stars[i]
+		language := item.GetLanguage()
+		if language == "" {
+			language = NoLanguage
+		}
+		stats.Languages[language]++
+		updated := parseTime(item.UpdatedAt)
+		if updated.IsZero() {
+			continue
+		}
+		if stats.NewestUpdate.IsZero() || updated.After(stats.NewestUpdate) {
+			stats.NewestUpdate = updated
+		}
+		if stats.OldestUpdate.IsZero() || updated.Before(stats.OldestUpdate) {
+			stats.OldestUpdate = updated
+		}
+	}
+	if len(stars) == 0 {
+		return stats
+	}
+	stats.AverageStars = float64(stats.TotalStars) / float64(len(stars))
+	sort.Ints(stars)
+	middle := len(stars) / 2
+	if len(stars)%2 == 0 {
+		stats.MedianStars = float64(stars[middle-1]+stars[middle]) / 2
+	} else {
+		stats.MedianStars = float64(stars[middle])
+	}
+	return stats
+}
@@ -0,0 +1,247 @@
+package lib_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func names(items []lib.Item) []string {
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.GetRepositoryName())
+	}
+	return names
+}
+
+func TestDedupe(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "ryo-ma/lazyhub", DataSource: lib.SourceOfficial},
+		{FullName: "jroimartin/gocui"},
+		{Name: "Ryo-Ma / LazyHub", DataSource: lib.SourceTrending},
+		{FullName: "golang/go"},
+		{URL: "https://github.com/jroimartin/gocui"},
+	}}
+	result.Dedupe()
+	if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui", "golang/go"}; !reflect.DeepEqual(names(result.Items), want) {
+		t.Errorf("items = %v, want %v", names(result.Items), want)
+	}
+	if result.Items[0].DataSource != lib.SourceOfficial {
+		t.Error("Dedupe did not keep the first occurrence")
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	items := []lib.Item{
+		{FullName: "b/middle", StargazersCount: 50, UpdatedAt: "2020-03-01T00:00:00Z"},
+		{Name: "a / trending", Stars: "1,200", DataSource: lib.SourceTrending},
+		{FullName: "C/oldest", StargazersCount: 5, UpdatedAt: "2019-01-01T00:00:00Z"},
+		{FullName: "d/newest", StargazersCount: 500, UpdatedAt: "2021-01-01T00:00:00Z"},
+	}
+	tests := []struct {
+		field      string
+		descending bool
+		want       []string
+	}{
+		{"stars", false, []string{"C/oldest", "b/middle", "d/newest", "a/trending"}},
+		{"stars", true, []string{"a/trending", "d/newest", "b/middle", "C/oldest"}},
+		{"name", false, []string{"a/trending", "b/middle", "C/oldest", "d/newest"}},
+		{"name", true, []string{"d/newest", "C/oldest", "b/middle", "a/trending"}},
+		{"updated", false, []string{"a/trending", "C/oldest", "b/middle", "d/newest"}},
+		{"updated", true, []string{"d/newest", "b/middle", "C/oldest", "a/trending"}},
+	}
+	for _, test := range tests {
+		result := &lib.Result{Items: append([]lib.Item{}, items...)}
+		if err := result.SortBy(test.field, test.descending); err != nil {
+			t.Fatal(err)
+		}
+		if got := names(result.Items); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SortBy(%q, %v) = %v, want %v", test.field, test.descending, got, test.want)
+		}
+	}
+	if err := (&lib.Result{}).SortBy("forks", false); err == nil {
+		t.Error("SortBy(\"forks\"): want an error")
+	}
+}
+
+func TestFilterStars(t *testing.T) {
+	result := &lib.Result{TotalCount: 4, Items: []lib.Item{
+		{FullName: "a/official", StargazersCount: 1500, DataSource: lib.SourceOfficial},
+		{Name: "b / trending", Stars: "999", DataSource: lib.SourceTrending},
+		{Name: "c / trending", Stars: "1,000", DataSource: lib.SourceTrending},
+		{FullName: "d/official", StargazersCount: 10, DataSource: lib.SourceOfficial},
+	}}
+	filtered := result.FilterStars(1000)
+	if want := []string{"a/official", "c/trending"}; !reflect.DeepEqual(names(filtered.Items), want) {
+		t.Errorf("items = %v, want %v", names(filtered.Items), want)
+	}
+	if len(result.Items) != 4 {
+		t.Errorf("FilterStars modified the original result: %v", names(result.Items))
+	}
+}
+
+func TestFilterLanguage(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "a/go", Language: "Go"},
+		{Name: "b / rust", Lang: "Rust", DataSource: lib.SourceTrending},
+		{FullName: "c/python", Language: "Python"},
+		{FullName: "d/none"},
+	}}
+	if got, want := names(result.FilterLanguage("rust", "GO").Items), []string{"a/go", "b/rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterLanguage(rust, GO) = %v, want %v", got, want)
+	}
+	if got, want := names(result.FilterLanguage("python", "").Items), []string{"c/python", "d/none"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterLanguage(python, \"\") = %v, want %v", got, want)
+	}
+	if got := result.FilterLanguage().Items; len(got) != 0 {
+		t.Errorf("FilterLanguage() = %v, want no items", names(got))
+	}
+}
+
+func TestTop(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "a/low", StargazersCount: 10},
+		{Name: "b / trending", Stars: "2k", DataSource: lib.SourceTrending},
+		{FullName: "c/mid", StargazersCount: 500},
+		{FullName: "d/mid", StargazersCount: 500},
+	}}
+	original := names(result.Items)
+
+	if got, want := names(result.Top(3).Items), []string{"b/trending", "c/mid", "d/mid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Top(3) = %v, want %v", got, want)
+	}
+	if got := result.Top(10).Items; len(got) != 4 {
+		t.Errorf("Top(10) returned %d items, want all 4", len(got))
+	}
+	if got := result.Top(0).Items; len(got) != 0 {
+		t.Errorf("Top(0) = %v, want none", names(got))
+	}
+	if !reflect.DeepEqual(names(result.Items), original) {
+		t.Errorf("Top modified the original result: %v", names(result.Items))
+	}
+}
+
+func TestGroupByLanguage(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "a/go", Language: "Go"},
+		{FullName: "b/rust", Language: "Rust"},
+		{FullName: "c/none"},
+		{Name: "d / go", Lang: "Go", DataSource: lib.SourceTrending},
+		{FullName: "e/none"},
+	}}
+	groups := result.GroupByLanguage()
+	want := map[string][]string{
+		"Go":           {"a/go", "d/go"},
+		"Rust":         {"b/rust"},
+		lib.NoLanguage: {"c/none", "e/none"},
+	}
+	if len(groups) != len(want) {
+		t.Errorf("groups = %v, want %d languages", groups, len(want))
+	}
+	for language, wantNames := range want {
+		if got := names(groups[language]); !reflect.DeepEqual(got, wantNames) {
+			t.Errorf("groups[%q] = %v, want %v", language, got, wantNames)
+		}
+	}
+}
+
+func TestFilterAndEach(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "a/go-big", Language: "Go", StargazersCount: 5000},
+		{FullName: "b/go-small", Language: "Go", StargazersCount: 10},
+		{FullName: "c/rust-big", Language: "Rust", StargazersCount: 9000},
+		{Name: "d / go-trending", Lang: "Go", Stars: "1.5k", DataSource: lib.SourceTrending},
+	}}
+	popularGo := result.Filter(func(item lib.Item) bool {
+		return item.GetLanguage() == "Go" && item.GetStars() > 1000
+	})
+	var visited []string
+	popularGo.Each(func(item lib.Item) { visited = append(visited, item.GetRepositoryName()) })
+	if want := []string{"a/go-big", "d/go-trending"}; !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited = %v, want %v", visited, want)
+	}
+	if len(result.Items) != 4 {
+		t.Errorf("Filter modified the original result: %v", names(result.Items))
+	}
+}
+
+func TestItemEqual(t *testing.T) {
+	official := lib.Item{FullName: "ryo-ma/lazyhub", StargazersCount: 1200, DataSource: lib.SourceOfficial}
+	same := []lib.Item{
+		{Name: "Ryo-Ma / LazyHub", Stars: "1,200", DataSource: lib.SourceTrending},
+		{URL: "https://github.com/ryo-ma/lazyhub", Stars: "1.2k", DataSource: lib.SourceTrending},
+		{FullName: " ryo-ma/lazyhub ", StargazersCount: 1200, DataSource: lib.SourceGraphQL},
+	}
+	for _, other := range same {
+		if !official.Equal(other) || !official.EqualStars(other) {
+			t.Errorf("%+v: Equal = %v, EqualStars = %v; want both true", other, official.Equal(other), official.EqualStars(other))
+		}
+	}
+	moreStars := lib.Item{Name: "ryo-ma / lazyhub", Stars: "1,300", DataSource: lib.SourceTrending}
+	if !official.Equal(moreStars) || official.EqualStars(moreStars) {
+		t.Error("an item with different stars should be Equal but not EqualStars")
+	}
+	if official.Equal(lib.Item{FullName: "ryo-ma/lazyhub-fork"}) {
+		t.Error("a different repository should not be Equal")
+	}
+}
+
+func TestResultDiff(t *testing.T) {
+	oldResult := &lib.Result{Items: []lib.Item{
+		{FullName: "a/kept", StargazersCount: 10},
+		{FullName: "b/rising", StargazersCount: 100},
+		{FullName: "c/dropped", StargazersCount: 5},
+	}}
+	newResult := &lib.Result{Items: []lib.Item{
+		{Name: "a / kept", Stars: "10", DataSource: lib.SourceTrending},
+		{FullName: "b/rising", StargazersCount: 150},
+		{FullName: "d/new", StargazersCount: 1},
+	}}
+	diff := lib.ResultDiff(oldResult, newResult)
+	if _, ok := diff.Added["d/new"]; len(diff.Added) != 1 || !ok {
+		t.Errorf("Added = %v, want d/new", diff.Added)
+	}
+	if _, ok := diff.Removed["c/dropped"]; len(diff.Removed) != 1 || !ok {
+		t.Errorf("Removed = %v, want c/dropped", diff.Removed)
+	}
+	if change, ok := diff.StarsChanged["b/rising"]; len(diff.StarsChanged) != 1 || !ok || change.OldStars != 100 || change.NewStars != 150 {
+		t.Errorf("StarsChanged = %v, want b/rising from 100 to 150", diff.StarsChanged)
+	}
+
+	diff = lib.ResultDiff(nil, newResult)
+	if len(diff.Added) != 3 || len(diff.Removed) != 0 || len(diff.StarsChanged) != 0 {
+		t.Errorf("diff from nil = %+v, want everything added", diff)
+	}
+}
+
+func TestStats(t *testing.T) {
+	result := &lib.Result{Items: []lib.Item{
+		{FullName: "a/go", Language: "Go", StargazersCount: 100, UpdatedAt: "2020-03-01T00:00:00Z"},
+		{FullName: "b/go", Language: "Go", StargazersCount: 300, UpdatedAt: "2021-01-02T03:04:05Z"},
+		{Name: "c / rust", Lang: "Rust", Stars: "1k", DataSource: lib.SourceTrending},
+		{FullName: "d/none", StargazersCount: 0, UpdatedAt: "last tuesday"},
+	}}
+	stats := result.Stats()
+	want := lib.Stats{
+		Count:        4,
+		TotalStars:   1400,
+		AverageStars: 350,
+		MedianStars:  200,
+		Languages:    map[string]int{"Go": 2, "Rust": 1, lib.NoLanguage: 1},
+		NewestUpdate: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		OldestUpdate: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats = %+v, want %+v", stats, want)
+	}
+
+	result.Items = result.Items[:3]
+	if stats := result.Stats(); stats.MedianStars != 300 {
+		t.Errorf("odd MedianStars = %v, want 300", stats.MedianStars)
+	}
+	if stats := (&lib.Result{}).Stats(); stats.Count != 0 || stats.AverageStars != 0 || !stats.NewestUpdate.IsZero() {
+		t.Errorf("empty Stats = %+v", stats)
+	}
+}
@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// Rand, when set, replaces the global source used for jitter. A
+	// *rand.Rand is not safe for concurrent use, so share it only between
+	// requests that never run at the same time.
+	Rand *rand.Rand
+}
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+func (policy RetryPolicy) attempts() int {
+	if policy.MaxAttempts < 1 {
+		return 1
+	}
+	return policy.MaxAttempts
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	base := policy.BaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+	max := policy.MaxDelay
+	if max <= 0 {
+		max = defaultRetryMaxDelay
+	}
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+func (policy RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter
+		}
+	}
+	return policy.jitter(policy.backoff(attempt))
+}
+
+// jitter picks a delay uniformly from [0, max] ("full jitter") so that
+// clients failing together do not retry in lockstep.
+func (policy RetryPolicy) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	if policy.Rand != nil {
+		return time.Duration(policy.Rand.Int63n(int64(max) + 1))
+	}
+	return time.Duration(rand.Int63n(int64(max) + 1))
+}
+
+// RetryTransport applies a RetryPolicy at the transport level so it can be
+// composed with other middleware. Client methods behave the same whether
+// retries happen here or through Client.Retry.
+type RetryTransport struct {
+	Base   http.RoundTripper
+	Policy RetryPolicy
+}
+
+func (transport *RetryTransport) base() http.RoundTripper {
+	if transport.Base != nil {
+		return transport.Base
+	}
+	return http.DefaultTransport
+}
+
+func (transport *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := transport.Policy.attempts()
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+		resp, err := transport.base().RoundTrip(attemptReq)
+		if attempt >= attempts {
+			return resp, err
+		}
+		classified := err
+		if err == nil {
+			var peekErr error
+			if classified, peekErr = peekRateLimitError(resp); peekErr != nil {
+				return nil, peekErr
+			}
+		}
+		if !shouldRetry(req.Context(), resp, classified) {
+			return resp, err
+		}
+		delay := transport.Policy.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// peekRateLimitError reads the body of a 403 or 429 so RoundTrip can tell
+// a secondary rate limit apart, the same way Client.Retry sees it through
+// the APIError. The body is put back, decompressed, for the caller.
+func peekRateLimitError(resp *http.Response) (apiError error, err error) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil, nil
+	}
+	body, err := readBody(resp, DefaultMaxResponseBytes)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return newAPIError(resp, body), nil
+}
+
+// shouldRetry also retries secondary rate limits that carry a Retry-After
+// header, which delay honors. Without one GitHub asks clients to back off
+// for minutes, so the error is returned instead of retrying in a tight loop.
+// An oversized body would only be downloaded again, so ErrResponseTooLarge
+// is never retried.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrResponseTooLarge) {
+		return false
+	}
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.IsSecondaryRateLimit() {
+		if resp == nil {
+			return false
+		}
+		_, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+		return ok
+	}
+	if resp == nil {
+		return err != nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryDelayJitter(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		Rand:      rand.New(rand.NewSource(1)),
+	}
+	for attempt := 1; attempt <= 6; attempt++ {
+		max := policy.backoff(attempt)
+		distinct := map[time.Duration]bool{}
+		for i := 0; i < 50; i++ {
+			delay := policy.delay(attempt, nil)
+			if delay < 0 || delay > max {
+				t.Fatalf("attempt %d: delay %v outside [0, %v]", attempt, delay, max)
+			}
+			distinct[delay] = true
+		}
+		if len(distinct) < 2 {
+			t.Errorf("attempt %d: delays are not jittered", attempt)
+		}
+	}
+	if max := policy.backoff(6); max != time.Second {
+		t.Errorf("backoff(6) = %v, want it capped at MaxDelay", max)
+	}
+
+	seeded := func() RetryPolicy {
+		return RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Rand: rand.New(rand.NewSource(1))}
+	}
+	if a, b := seeded().delay(3, nil), seeded().delay(3, nil); a != b {
+		t.Errorf("same seed gave delays %v and %v, want them deterministic", a, b)
+	}
+}
+
+func TestRetryDelayHonorsRetryAfter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Hour, Rand: rand.New(rand.NewSource(1))}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"2"}}}
+	if delay := policy.delay(1, resp); delay != 2*time.Second {
+		t.Errorf("delay = %v, want Retry-After without jitter", delay)
+	}
+}
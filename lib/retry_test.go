@@ -0,0 +1,212 @@
+package lib_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+const secondaryRateLimitMessage = `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`
+
+// secondaryRateLimitServer answers with a secondary rate limit until calls
+// reaches failures, then with an empty search result.
+func secondaryRateLimitServer(t *testing.T, status int, retryAfter string, failures int32, calls *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(calls, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(secondaryRateLimitMessage))
+			return
+		}
+		w.Write([]byte(`{"total_count":0,"items":[]}`))
+	}))
+}
+
+func TestSecondaryRateLimitIsDetected(t *testing.T) {
+	var calls int32
+	server := secondaryRateLimitServer(t, http.StatusForbidden, "", 1, &calls)
+	defer server.Close()
+	client, err := lib.NewClient(lib.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Retry = lib.RetryPolicy{MaxAttempts: 3}
+
+	_, err = client.SearchRepository("go")
+	if !errors.Is(err, lib.ErrSecondaryRateLimit) {
+		t.Fatalf("err = %v, want ErrSecondaryRateLimit", err)
+	}
+	var apiError *lib.APIError
+	if !errors.As(err, &apiError) || !apiError.Temporary() {
+		t.Errorf("err = %#v, want a temporary *APIError", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1: no retry without Retry-After", calls)
+	}
+}
+
+func TestClientRetryHonorsSecondaryRateLimitRetryAfter(t *testing.T) {
+	for _, retryAfter := range []string{"0", "1"} {
+		var calls int32
+		server := secondaryRateLimitServer(t, http.StatusForbidden, retryAfter, 1, &calls)
+		client, err := lib.NewClient(lib.WithBaseURL(server.URL))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Retry = lib.RetryPolicy{MaxAttempts: 3}
+		if _, err := client.SearchRepository("go"); err != nil {
+			t.Errorf("Retry-After %s: %v", retryAfter, err)
+		}
+		if calls != 2 {
+			t.Errorf("Retry-After %s: calls = %d, want 2", retryAfter, calls)
+		}
+		server.Close()
+	}
+}
+
+func TestRetryTransportClassifiesSecondaryRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		wantCalls  int32
+		wantStatus int
+	}{
+		{"403 with Retry-After", http.StatusForbidden, "0", 2, http.StatusOK},
+		{"403 without Retry-After", http.StatusForbidden, "", 1, http.StatusForbidden},
+		{"429 without Retry-After", http.StatusTooManyRequests, "", 1, http.StatusTooManyRequests},
+	}
+	for _, test := range tests {
+		var calls int32
+		server := secondaryRateLimitServer(t, test.status, test.retryAfter, 1, &calls)
+		httpClient := &http.Client{Transport: &lib.RetryTransport{Policy: lib.RetryPolicy{MaxAttempts: 3}}}
+		client, err := lib.NewClient(lib.WithBaseURL(server.URL), lib.WithHTTPClient(httpClient))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.SearchRepository("go")
+		if calls != test.wantCalls {
+			t.Errorf("%s: calls = %d, want %d", test.name, calls, test.wantCalls)
+		}
+		if test.wantStatus == http.StatusOK && err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+		if test.wantStatus != http.StatusOK && !errors.Is(err, lib.ErrSecondaryRateLimit) {
+			t.Errorf("%s: err = %v, want ErrSecondaryRateLimit with the body intact", test.name, err)
+		}
+		server.Close()
+	}
+}
+
+// flakyTransport fails with status for the first failures calls and then
+// answers with an empty search result.
+func flakyTransport(status int, failures int32, calls *int32) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		code, body := http.StatusOK, `{"total_count":0,"items":[]}`
+		if atomic.AddInt32(calls, 1) <= failures {
+			code, body = status, `{"message":"unavailable"}`
+		}
+		return &http.Response{
+			StatusCode: code,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestClientRetryBacksOff(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantCalls int32
+		wantErr   bool
+	}{
+		{http.StatusServiceUnavailable, 3, false},
+		{http.StatusTooManyRequests, 3, false},
+		{http.StatusUnprocessableEntity, 1, true},
+	}
+	for _, test := range tests {
+		var calls int32
+		client, err := lib.NewClient(lib.WithHTTPClient(&http.Client{Transport: flakyTransport(test.status, 2, &calls)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Retry = lib.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+		_, err = client.SearchRepository("go")
+		if (err != nil) != test.wantErr {
+			t.Errorf("status %d: err = %v, want error %v", test.status, err, test.wantErr)
+		}
+		if calls != test.wantCalls {
+			t.Errorf("status %d: calls = %d, want %d", test.status, calls, test.wantCalls)
+		}
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls int32
+	transport := &lib.RetryTransport{
+		Base:   flakyTransport(http.StatusServiceUnavailable, 1, &calls),
+		Policy: lib.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	}
+	req, err := http.NewRequest("GET", "https://api.github.com/search/repositories?q=go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != `{"total_count":0,"items":[]}` {
+		t.Errorf("response = %d %q, want the final 200 body", resp.StatusCode, body)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	calls = 0
+	client, err := lib.NewClient(lib.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchRepository("go"); err != nil {
+		t.Errorf("SearchRepository through RetryTransport: %v", err)
+	}
+}
+
+func TestOversizedResponseIsNotRetried(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	client.MaxResponseBytes = 100
+	client.Retry = lib.RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond}
+
+	if _, err := client.SearchRepository("go"); !errors.Is(err, lib.ErrResponseTooLarge) {
+		t.Fatalf("err = %v, want ErrResponseTooLarge", err)
+	}
+	if calls != 1 {
+		t.Errorf("made %d requests, want 1", calls)
+	}
+}
@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ScoreWeights tunes ActivityScore. Stars weighs log10(1+stars), Recency
+// weighs a decay from 1 (updated now) that halves every HalfLife, and Issues
+// subtracts the share of open issues relative to stars.
+type ScoreWeights struct {
+	Stars    float64
+	Recency  float64
+	Issues   float64
+	HalfLife time.Duration
+}
+
+var DefaultScoreWeights = ScoreWeights{
+	Stars:    1,
+	Recency:  2,
+	Issues:   1,
+	HalfLife: 90 * 24 * time.Hour,
+}
+
+// ActivityScore combines stars, how recently the repository was updated and
+// its open issues into one number; higher is more worth a look. Items
+// without a valid UpdatedAt get no recency credit.
+func (item *Item) ActivityScore(weights ScoreWeights, now time.Time) float64 {
+	stars := float64(item.GetStars())
+	score := weights.Stars * math.Log10(1+stars)
+	if updated := parseTime(item.UpdatedAt); !updated.IsZero() && weights.HalfLife > 0 {
+		age := now.Sub(updated)
+		if age < 0 {
+			age = 0
+		}
+		score += weights.Recency * math.Exp2(-float64(age)/float64(weights.HalfLife))
+	}
+	if item.OpenIssuesCount > 0 {
+		issues := float64(item.OpenIssuesCount)
+		score -= weights.Issues * issues / (issues + stars)
+	}
+	return score
+}
+
+// SortByScore sorts items by descending ActivityScore.
+func (result *Result) SortByScore(weights ScoreWeights, now time.Time) {
+	sort.SliceStable(result.Items, func(i, j int) bool {
+		return result.Items[i].ActivityScore(weights, now) > result.Items[j].ActivityScore(weights, now)
+	})
+}
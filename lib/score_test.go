@@ -0,0 +1,35 @@
+package lib_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+)
+
+func TestActivityScoreOrdering(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []lib.Item{
+		{FullName: "a/no-date", StargazersCount: 100},
+		{FullName: "b/stale", StargazersCount: 5000, UpdatedAt: "2019-01-01T00:00:00Z"},
+		{FullName: "c/issues", StargazersCount: 1000, OpenIssuesCount: 1000, UpdatedAt: "2020-12-31T00:00:00Z"},
+		{FullName: "d/fresh", StargazersCount: 1000, UpdatedAt: "2020-12-31T00:00:00Z"},
+	}
+	tests := []struct {
+		name    string
+		weights lib.ScoreWeights
+		want    []string
+	}{
+		{"default", lib.DefaultScoreWeights, []string{"d/fresh", "c/issues", "b/stale", "a/no-date"}},
+		{"stars only", lib.ScoreWeights{Stars: 1}, []string{"b/stale", "c/issues", "d/fresh", "a/no-date"}},
+		{"issues only", lib.ScoreWeights{Issues: 1}, []string{"a/no-date", "b/stale", "d/fresh", "c/issues"}},
+	}
+	for _, test := range tests {
+		result := &lib.Result{Items: append([]lib.Item{}, items...)}
+		result.SortByScore(test.weights, now)
+		if got := names(result.Items); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: order = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
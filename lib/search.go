@@ -0,0 +1,241 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type SearchOptions struct {
+	Page    int
+	PerPage int
+	Sort    string
+	Order   string
+
+	Language     string
+	MinStars     int
+	CreatedAfter time.Time
+	PushedWithin time.Duration
+	Topic        string
+
+	// Forks and archived repositories are excluded from non-empty queries
+	// unless these are set or the query already has a fork: or archived:
+	// qualifier.
+	IncludeForks    bool
+	IncludeArchived bool
+}
+
+func (opts SearchOptions) Query(query string) string {
+	return opts.QueryAt(query, time.Now())
+}
+
+// QueryAt is Query with an explicit current time for relative qualifiers
+// such as PushedWithin.
+func (opts SearchOptions) QueryAt(query string, now time.Time) string {
+	terms := []string{}
+	if query = strings.TrimSpace(query); query != "" {
+		terms = append(terms, query)
+	}
+	if opts.Language != "" {
+		terms = append(terms, qualifier("language", opts.Language))
+	}
+	if opts.Topic != "" {
+		terms = append(terms, qualifier("topic", opts.Topic))
+	}
+	if opts.MinStars > 0 {
+		terms = append(terms, "stars:>="+strconv.Itoa(opts.MinStars))
+	}
+	if !opts.CreatedAfter.IsZero() {
+		terms = append(terms, "created:>"+opts.CreatedAfter.UTC().Format("2006-01-02"))
+	}
+	if opts.PushedWithin > 0 {
+		terms = append(terms, "pushed:>"+now.Add(-opts.PushedWithin).UTC().Format("2006-01-02"))
+	}
+	// The exclusions only narrow a search, so an otherwise empty query stays
+	// empty and callers such as Discover can still skip searching.
+	if len(terms) == 0 {
+		return ""
+	}
+	if !hasQualifier(query, "fork") {
+		terms = append(terms, "fork:"+strconv.FormatBool(opts.IncludeForks))
+	}
+	if !opts.IncludeArchived && !hasQualifier(query, "archived") {
+		terms = append(terms, "archived:false")
+	}
+	return strings.Join(terms, " ")
+}
+
+func hasQualifier(query string, key string) bool {
+	prefix := key + ":"
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(strings.TrimPrefix(term, "-"), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifier quotes values containing anything beyond letters, digits, '.',
+// '-' and '_' (such as "c++" or "visual basic"), escaping embedded quotes.
+func qualifier(key string, value string) string {
+	value = strings.TrimSpace(value)
+	if strings.IndexFunc(value, needsQuoting) >= 0 {
+		value = `"` + qualifierEscaper.Replace(value) + `"`
+	}
+	return key + ":" + value
+}
+
+var qualifierEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func needsQuoting(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-' && r != '_'
+}
+
+func (client *Client) SearchByTopic(topic string, opts SearchOptions) (*Result, error) {
+	return client.SearchByTopicContext(context.Background(), topic, opts)
+}
+
+func (client *Client) SearchByTopicContext(ctx context.Context, topic string, opts SearchOptions) (*Result, error) {
+	if strings.TrimSpace(topic) == "" {
+		return nil, errors.New("topic must not be empty")
+	}
+	opts.Topic = topic
+	return client.SearchRepositoryWithOptionsContext(ctx, "", opts)
+}
+
+var ErrNoTopics = errors.New("repository has no topics")
+
+// GitHub rejects search queries with more than five AND, OR or NOT operators.
+const maxRelatedTopics = 5
+
+func (client *Client) GetRelated(item Item, limit int) (*Result, error) {
+	return client.GetRelatedContext(context.Background(), item, limit)
+}
+
+// GetRelatedContext searches for the most starred repositories sharing one of
+// item's topics. Items without topics yield an empty Result and ErrNoTopics.
+func (client *Client) GetRelatedContext(ctx context.Context, item Item, limit int) (*Result, error) {
+	if len(item.Topics) == 0 {
+		return &Result{}, ErrNoTopics
+	}
+	if limit <= 0 {
+		limit = defaultPerPage
+	}
+	topics := item.Topics
+	if len(topics) > maxRelatedTopics {
+		topics = topics[:maxRelatedTopics]
+	}
+	terms := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		terms = append(terms, qualifier("topic", topic))
+	}
+	result, err := client.SearchRepositoryWithOptionsContext(ctx, strings.Join(terms, " OR "), SearchOptions{
+		PerPage: limit + 1,
+		Sort:    "stars",
+		Order:   "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	related := *result
+	related.Items = []Item{}
+	for _, candidate := range result.Items {
+		if candidate.Equal(item) {
+			continue
+		}
+		if len(related.Items) == limit {
+			break
+		}
+		related.Items = append(related.Items, candidate)
+	}
+	return &related, nil
+}
+
+const maxSearchResults = 1000
+
+type SearchIterator struct {
+	client  *Client
+	query   string
+	opts    SearchOptions
+	fetched int
+	next    string
+	done    bool
+}
+
+func (client *Client) SearchRepositoryIter(query string, opts SearchOptions) *SearchIterator {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PerPage < 1 {
+		opts.PerPage = defaultPerPage
+	}
+	return &SearchIterator{client: client, query: query, opts: opts}
+}
+
+// Next returns the next page of items, or io.EOF once the results or the
+// search API's 1000 result cap are exhausted.
+func (it *SearchIterator) Next(ctx context.Context) ([]Item, error) {
+	if it.done {
+		return nil, io.EOF
+	}
+	var result *Result
+	var err error
+	if it.next != "" {
+		result, err = it.client.searchRepositoryURL(ctx, it.next, it.opts.Page, it.opts.PerPage)
+	} else {
+		result, err = it.client.SearchRepositoryWithOptionsContext(ctx, it.query, it.opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	it.fetched += len(result.Items)
+	it.opts.Page++
+	it.next = result.Links.Next
+	if len(result.Items) == 0 || !result.HasNextPage() || it.fetched >= maxSearchResults {
+		it.done = true
+	}
+	if len(result.Items) == 0 {
+		return nil, io.EOF
+	}
+	return result.Items, nil
+}
+
+type CodeItem struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	SHA        string `json:"sha"`
+	HTMLURL    string `json:"html_url"`
+	Repository Item   `json:"repository"`
+}
+
+type CodeResult struct {
+	TotalCount        int        `json:"total_count"`
+	IncompleteResults bool       `json:"incomplete_results"`
+	Items             []CodeItem `json:"items"`
+}
+
+func (client *Client) SearchCode(query string, opts ListOptions) (*CodeResult, error) {
+	return client.SearchCodeContext(context.Background(), query, opts)
+}
+
+// SearchCodeContext searches file contents. GitHub only serves code search
+// to authenticated requests, so Token must be set.
+func (client *Client) SearchCodeContext(ctx context.Context, query string, opts ListOptions) (*CodeResult, error) {
+	url := client.apiURL("search", "code")
+	q := url.Query()
+	q.Set("q", query)
+	opts.encode(q)
+	url.RawQuery = q.Encode()
+	var result CodeResult
+	if _, err := client.getJSON(ctx, url.String(), &result); err != nil {
+		return nil, err
+	}
+	for i := range result.Items {
+		result.Items[i].Repository.DataSource = SourceOfficial
+	}
+	return &result, nil
+}
@@ -0,0 +1,274 @@
+package lib_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestSearchOptionsQueryForkAndArchived(t *testing.T) {
+	tests := []struct {
+		query string
+		opts  lib.SearchOptions
+		want  string
+	}{
+		{"go", lib.SearchOptions{}, "go fork:false archived:false"},
+		{"go", lib.SearchOptions{IncludeForks: true}, "go fork:true archived:false"},
+		{"go", lib.SearchOptions{IncludeForks: true, IncludeArchived: true}, "go fork:true"},
+		{"go fork:only", lib.SearchOptions{}, "go fork:only archived:false"},
+		{"go -archived:true", lib.SearchOptions{}, "go -archived:true fork:false"},
+		{"", lib.SearchOptions{}, ""},
+		{"", lib.SearchOptions{IncludeForks: true}, ""},
+		{"", lib.SearchOptions{Language: "go"}, "language:go fork:false archived:false"},
+	}
+	for _, test := range tests {
+		if got := test.opts.Query(test.query); got != test.want {
+			t.Errorf("Query(%q) with %+v = %q, want %q", test.query, test.opts, got, test.want)
+		}
+	}
+}
+
+func TestDiscoverWithoutQuerySkipsSearch(t *testing.T) {
+	server := lazyhubtest.NewServer()
+	defer server.Close()
+	var searches int32
+	server.Handle("/search/repositories", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&searches, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(lazyhubtest.SearchResponse))
+	}))
+	client, err := server.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.Discover(lib.DiscoverOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&searches); n != 0 {
+		t.Errorf("Discover made %d search requests, want 0", n)
+	}
+	if len(result.Items) != 1 || result.Items[0].GetRepositoryName() != "ryo-ma/lazyhub" {
+		t.Errorf("Discover items = %+v, want only the trending item", result.Items)
+	}
+}
+
+func TestSearchOptionsQualifiers(t *testing.T) {
+	const exclusions = " fork:false archived:false"
+	tests := []struct {
+		opts lib.SearchOptions
+		want string
+	}{
+		{lib.SearchOptions{Language: "go"}, "language:go"},
+		{lib.SearchOptions{Topic: "cli"}, "topic:cli"},
+		{lib.SearchOptions{MinStars: 100}, "stars:>=100"},
+		{lib.SearchOptions{CreatedAfter: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)}, "created:>2020-06-01"},
+		{lib.SearchOptions{Language: "go", Topic: "tui", MinStars: 10}, "language:go topic:tui stars:>=10"},
+	}
+	for _, test := range tests {
+		if got := test.opts.Query("  tetris "); got != "tetris "+test.want+exclusions {
+			t.Errorf("Query with %+v = %q, want %q", test.opts, got, "tetris "+test.want+exclusions)
+		}
+	}
+}
+
+func TestSearchRepositoryWithOptionsSendsQuery(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	_, err := client.SearchRepositoryWithOptions("tetris", lib.SearchOptions{Language: "go", MinStars: 5, Page: 2, PerPage: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := query.Get("q"), "tetris language:go stars:>=5 fork:false archived:false"; got != want {
+		t.Errorf("q = %q, want %q", got, want)
+	}
+	if query.Get("page") != "2" || query.Get("per_page") != "50" {
+		t.Errorf("page = %q, per_page = %q; want 2 and 50", query.Get("page"), query.Get("per_page"))
+	}
+}
+
+func TestSearchIterator(t *testing.T) {
+	var searches int32
+	paged := pagedSearchHandler(t)
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&searches, 1)
+			paged.ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	it := client.SearchRepositoryIter("go", lib.SearchOptions{PerPage: 1})
+	var all []lib.Item
+	for {
+		items, err := it.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, items...)
+	}
+	if want := []string{"owner/first", "owner/second"}; !reflect.DeepEqual(names(all), want) {
+		t.Errorf("items = %v, want %v", names(all), want)
+	}
+	if searches != 2 {
+		t.Errorf("made %d requests, want 2", searches)
+	}
+	if _, err := it.Next(context.Background()); err != io.EOF {
+		t.Errorf("Next after completion = %v, want io.EOF", err)
+	}
+}
+
+func TestSearchByTopic(t *testing.T) {
+	var query string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query().Get("q")
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	if _, err := client.SearchByTopic("tui", lib.SearchOptions{Language: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "language:go topic:tui fork:false archived:false"; query != want {
+		t.Errorf("q = %q, want %q", query, want)
+	}
+	if _, err := client.SearchByTopic("  ", lib.SearchOptions{}); err == nil {
+		t.Error("SearchByTopic with an empty topic: want an error")
+	}
+}
+
+func TestSearchCode(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/code": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`{"total_count":1,"incomplete_results":false,"items":[{
+				"name": "githubclient.go",
+				"path": "lib/githubclient.go",
+				"sha": "abc123",
+				"html_url": "https://github.com/ryo-ma/lazyhub/blob/master/lib/githubclient.go",
+				"repository": {"full_name": "ryo-ma/lazyhub", "html_url": "https://github.com/ryo-ma/lazyhub"}
+			}]}`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	client.Token = "secret"
+
+	result, err := client.SearchCode("NewClient repo:ryo-ma/lazyhub", lib.ListOptions{PerPage: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("q") != "NewClient repo:ryo-ma/lazyhub" || query.Get("per_page") != "5" {
+		t.Errorf("query = %v", query)
+	}
+	if result.TotalCount != 1 || len(result.Items) != 1 {
+		t.Fatalf("result = %+v, want one hit", result)
+	}
+	hit := result.Items[0]
+	if hit.Path != "lib/githubclient.go" || hit.HTMLURL != "https://github.com/ryo-ma/lazyhub/blob/master/lib/githubclient.go" {
+		t.Errorf("hit = %+v", hit)
+	}
+	if hit.Repository.GetRepositoryName() != "ryo-ma/lazyhub" || hit.Repository.DataSource != lib.SourceOfficial {
+		t.Errorf("hit.Repository = %+v", hit.Repository)
+	}
+}
+
+func TestGetRelated(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(`{"total_count":3,"items":[
+				{"full_name":"jesseduffield/lazygit","stargazers_count":20000},
+				{"full_name":"Ryo-Ma/LazyHub","stargazers_count":1200},
+				{"full_name":"jroimartin/gocui","stargazers_count":8000}
+			]}`).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	item := lib.Item{FullName: "ryo-ma/lazyhub", Topics: []string{"tui", "github"}}
+
+	related, err := client.GetRelated(item, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := query.Get("q"), "topic:tui OR topic:github fork:false archived:false"; got != want {
+		t.Errorf("q = %q, want %q", got, want)
+	}
+	if query.Get("sort") != "stars" || query.Get("order") != "desc" || query.Get("per_page") != "3" {
+		t.Errorf("query = %v, want sort=stars, order=desc and per_page=3", query)
+	}
+	if want := []string{"jesseduffield/lazygit", "jroimartin/gocui"}; !reflect.DeepEqual(names(related.Items), want) {
+		t.Errorf("related = %v, want %v without the original", names(related.Items), want)
+	}
+
+	related, err = client.GetRelated(lib.Item{FullName: "ryo-ma/lazyhub"}, 2)
+	if err != lib.ErrNoTopics || related == nil || len(related.Items) != 0 {
+		t.Errorf("no topics: GetRelated = %v, %v; want an empty result and ErrNoTopics", related, err)
+	}
+}
+
+func TestSearchOptionsPushedWithin(t *testing.T) {
+	now := time.Date(2020, 6, 10, 1, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	opts := lib.SearchOptions{PushedWithin: 30 * 24 * time.Hour}
+	if got, want := opts.QueryAt("go", now), "go pushed:>2020-05-10 fork:false archived:false"; got != want {
+		t.Errorf("QueryAt = %q, want %q", got, want)
+	}
+
+	var query string
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query().Get("q")
+			jsonBody(lazyhubtest.SearchResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	client.Now = func() time.Time { return now }
+	if _, err := client.SearchRepositoryWithOptions("go", opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := "go pushed:>2020-05-10 fork:false archived:false"; query != want {
+		t.Errorf("q = %q, want %q from the client clock", query, want)
+	}
+}
+
+func TestSearchOptionsQuotesQualifierValues(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"go", "language:go"},
+		{"c++", `language:"c++"`},
+		{"visual basic", `language:"visual basic"`},
+		{`say "hi"`, `language:"say \"hi\""`},
+		{"a:b", `language:"a:b"`},
+		{"objective-c", "language:objective-c"},
+	}
+	for _, test := range tests {
+		opts := lib.SearchOptions{Language: test.language, IncludeForks: true, IncludeArchived: true}
+		if got, want := opts.Query(""), test.want+" fork:true"; got != want {
+			t.Errorf("Language %q: Query = %q, want %q", test.language, got, want)
+		}
+	}
+}
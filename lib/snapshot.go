@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// LoadSnapshot reads a Result saved by Result.Save, or any file in the
+// search API response format, for offline use. Items without a DataSource
+// are treated as coming from the official API.
+func LoadSnapshot(path string) (*Result, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	for i := range result.Items {
+		if result.Items[i].DataSource == "" {
+			result.Items[i].DataSource = SourceOfficial
+		}
+	}
+	return &result, nil
+}
+
+func (result *Result) Save(path string) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
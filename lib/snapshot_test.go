@@ -0,0 +1,49 @@
+package lib_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	result := sampleResult()
+	result.TotalCount = 2
+	if err := result.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := lib.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.TotalCount != 2 || !reflect.DeepEqual(loaded.Items, result.Items) {
+		t.Errorf("loaded = %+v, want %+v", loaded, result)
+	}
+}
+
+func TestLoadSnapshotSearchResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	if err := ioutil.WriteFile(path, []byte(lazyhubtest.SearchResponse), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := lib.LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ryo-ma/lazyhub", "jroimartin/gocui"}; !reflect.DeepEqual(names(loaded.Items), want) {
+		t.Errorf("items = %v, want %v", names(loaded.Items), want)
+	}
+	for _, item := range loaded.Items {
+		if item.DataSource != lib.SourceOfficial {
+			t.Errorf("%s DataSource = %q, want %q", item.GetRepositoryName(), item.DataSource, lib.SourceOfficial)
+		}
+	}
+	if _, err := lib.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("missing file: want an error")
+	}
+}
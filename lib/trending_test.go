@@ -0,0 +1,315 @@
+package lib_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ryo-ma/lazyhub/lib"
+	"github.com/ryo-ma/lazyhub/lib/lazyhubtest"
+)
+
+type stubTrendingProvider struct {
+	items []lib.Item
+}
+
+func (provider stubTrendingProvider) TrendingRepositories(ctx context.Context, language string, since string) ([]lib.Item, error) {
+	return provider.items, nil
+}
+
+type checkedTrendingProvider struct {
+	stubTrendingProvider
+	err error
+}
+
+func (provider checkedTrendingProvider) TrendingHealthy(ctx context.Context) (bool, error) {
+	return provider.err == nil, provider.err
+}
+
+func TestTrendingHealthy(t *testing.T) {
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+	if healthy, err := client.TrendingHealthy(context.Background()); !healthy || err != nil {
+		t.Errorf("healthy backend: TrendingHealthy = %v, %v", healthy, err)
+	}
+
+	server.Handle("/repo", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		http.Error(w, "Application Error", http.StatusServiceUnavailable)
+	}))
+	if healthy, err := client.TrendingHealthy(context.Background()); healthy || err == nil {
+		t.Errorf("unhealthy backend: TrendingHealthy = %v, %v", healthy, err)
+	}
+}
+
+func TestTrendingHealthyUsesProvider(t *testing.T) {
+	var backendCalls int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&backendCalls, 1)
+			http.Error(w, "Application Error", http.StatusServiceUnavailable)
+		}),
+	})
+	defer server.Close()
+
+	client.TrendingProvider = stubTrendingProvider{}
+	if healthy, err := client.TrendingHealthy(context.Background()); !healthy || err != nil {
+		t.Errorf("provider without checker: TrendingHealthy = %v, %v", healthy, err)
+	}
+	down := errors.New("scraper down")
+	client.TrendingProvider = checkedTrendingProvider{err: down}
+	if healthy, err := client.TrendingHealthy(context.Background()); healthy || err != down {
+		t.Errorf("provider with checker: TrendingHealthy = %v, %v", healthy, err)
+	}
+	if backendCalls != 0 {
+		t.Errorf("default backend was checked %d times with a provider set", backendCalls)
+	}
+}
+
+func TestTrendingFallback(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Application Error", http.StatusServiceUnavailable)
+		}),
+	})
+	defer server.Close()
+	client.TrendingFallback = true
+
+	result, err := client.GetTrendingRepository("go", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Fallback || len(result.Items) == 0 {
+		t.Errorf("result = %+v, want fallback search items", result)
+	}
+
+	server.Handle("/search/repositories", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "search down", http.StatusInternalServerError)
+	}))
+	_, err = client.GetTrendingRepository("go", "daily")
+	errs, ok := err.(lib.MultiError)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("err = %#v, want a MultiError with the trending and search errors", err)
+	}
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError} {
+		var apiError *lib.APIError
+		if !errors.As(errs[i], &apiError) || apiError.StatusCode != want {
+			t.Errorf("errs[%d] = %v, want status %d", i, errs[i], want)
+		}
+	}
+}
+
+func TestTrendingFallbackErrorMatchesWrappedErrors(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "asleep", http.StatusServiceUnavailable)
+		}),
+		"/search/repositories": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`))
+		}),
+	})
+	defer server.Close()
+	client.TrendingFallback = true
+
+	_, err := client.GetTrendingRepository("go", "daily")
+	if !lib.IsNotFound(err) || !errors.Is(err, lib.ErrNotFound) {
+		t.Errorf("err = %v, want it to match ErrNotFound from the fallback search", err)
+	}
+	var apiError *lib.APIError
+	if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("errors.As = %v, want the trending 503 first", apiError)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.Handle("/search/repositories", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}))
+	if _, err := client.GetTrendingRepositoryContext(ctx, "go", "daily"); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled fallback: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestGetTrendingDevelopers(t *testing.T) {
+	var query url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/dev": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			jsonBody(lazyhubtest.DevelopersResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+
+	result, err := client.GetTrendingDevelopers("go", "weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("lang") != "go" || query.Get("since") != "weekly" {
+		t.Errorf("query = %v, want lang=go and since=weekly", query)
+	}
+	want := []lib.Developer{{
+		Username:    "ryo-ma",
+		Name:        "ryo-ma",
+		URL:         "https://github.com/ryo-ma",
+		Avatar:      "https://avatars.githubusercontent.com/u/1",
+		Repo:        "lazyhub",
+		RepoURL:     "https://github.com/ryo-ma/lazyhub",
+		Description: "lazyhub is a terminal UI client for GitHub",
+	}}
+	if !reflect.DeepEqual(result.Items, want) {
+		t.Errorf("developers = %+v, want %+v", result.Items, want)
+	}
+}
+
+func TestTrendingQueryDoesNotLeakBetweenCalls(t *testing.T) {
+	var queries []url.Values
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			queries = append(queries, r.URL.Query())
+			jsonBody(lazyhubtest.TrendingResponse).ServeHTTP(w, r)
+		}),
+	})
+	defer server.Close()
+	base := client.TrendingRepositoryURL.String()
+
+	if _, err := client.GetTrendingRepository("go", "weekly"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetTrendingRepository("rust", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("got %d requests, want 2", len(queries))
+	}
+	if got := queries[1].Get("lang"); got != "rust" {
+		t.Errorf("second lang = %q, want rust", got)
+	}
+	if got := queries[1].Get("since"); got != "" {
+		t.Errorf("second since = %q, leaked from the first call", got)
+	}
+	if got := client.TrendingRepositoryURL.String(); got != base {
+		t.Errorf("TrendingRepositoryURL = %s, want it unchanged from %s", got, base)
+	}
+}
+
+func TestGetTrendingRepositoryValidatesSince(t *testing.T) {
+	client, server := newTestClient(t, nil)
+	defer server.Close()
+
+	for _, since := range []string{"", "daily", "weekly", "monthly"} {
+		if _, err := client.GetTrendingRepository("go", since); err != nil {
+			t.Errorf("since %q: %v", since, err)
+		}
+	}
+	for _, since := range []string{"week", "Daily", "yearly"} {
+		if _, err := client.GetTrendingRepository("go", since); err == nil {
+			t.Errorf("since %q: want an error", since)
+		}
+		if _, err := client.GetTrendingDevelopers("go", since); err == nil {
+			t.Errorf("developers since %q: want an error", since)
+		}
+	}
+}
+
+func TestTrendingMalformedResponses(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"html", "text/html; charset=utf-8", "<html><body>Application Error</body></html>", "unexpected content type"},
+		{"empty", "application/json", "", "empty response"},
+		{"null", "application/json", "null", "empty response"},
+		{"malformed", "application/json", `{"items": [`, "malformed response"},
+	}
+	for _, test := range tests {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", test.contentType)
+			w.Write([]byte(test.body))
+		})
+		client, server := newTestClient(t, map[string]http.Handler{"/repo": handler, "/dev": handler})
+		if _, err := client.GetTrendingRepository("go", ""); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s repositories: err = %v, want %q", test.name, err, test.want)
+		}
+		if _, err := client.GetTrendingDevelopers("go", ""); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s developers: err = %v, want %q", test.name, err, test.want)
+		}
+		server.Close()
+	}
+}
+
+func TestTrendingLimit(t *testing.T) {
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": jsonBody(`{"items":[
+			{"repo_link":"https://github.com/a/one","stars":"3"},
+			{"repo_link":"https://github.com/b/two","stars":"2"},
+			{"repo_link":"https://github.com/c/three","stars":"1"}
+		]}`),
+	})
+	defer server.Close()
+
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		{2, []string{"a/one", "b/two"}},
+		{0, []string{"a/one", "b/two", "c/three"}},
+		{5, []string{"a/one", "b/two", "c/three"}},
+	}
+	for _, test := range tests {
+		result, err := client.GetTrendingRepositoryWithOptions(lib.TrendingOptions{Limit: test.limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(result.Items); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Limit %d: items = %v, want %v", test.limit, got, test.want)
+		}
+	}
+	if _, err := client.GetTrendingRepositoryWithOptions(lib.TrendingOptions{Limit: -1}); err == nil {
+		t.Error("negative limit: want an error")
+	}
+}
+
+func TestTrendingProvider(t *testing.T) {
+	var backendCalls int32
+	client, server := newTestClient(t, map[string]http.Handler{
+		"/repo": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&backendCalls, 1)
+		}),
+	})
+	defer server.Close()
+	client.TrendingProvider = stubTrendingProvider{items: []lib.Item{
+		{FullName: "synthetic/one", StargazersCount: 10},
+		{FullName: "synthetic/two", Stars: "2k"},
+	}}
+
+	result, err := client.GetTrendingRepository("go", "weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 2 || result.Items[0].GetRepositoryName() != "synthetic/one" {
+		t.Fatalf("items = %+v, want the provider's items", result.Items)
+	}
+	for _, item := range result.Items {
+		if item.DataSource != lib.SourceTrending {
+			t.Errorf("%s: DataSource = %q, want %q", item.GetRepositoryName(), item.DataSource, lib.SourceTrending)
+		}
+	}
+	if stars := result.Items[1].GetStars(); stars != 2000 {
+		t.Errorf("stars = %d, want 2000", stars)
+	}
+	if backendCalls != 0 {
+		t.Errorf("default backend was called %d times with a provider set", backendCalls)
+	}
+}
@@ -0,0 +1,7 @@
+package lib
+
+const version = "0.1.0"
+
+func Version() string {
+	return version
+}
@@ -2,10 +2,7 @@ package main
 
 import (
 	"log"
-	"os/exec"
-	"runtime"
 
-	"encoding/base64"
 	"github.com/atotto/clipboard"
 	"github.com/jroimartin/gocui"
 	"github.com/ryo-ma/lazyhub/lib"
@@ -41,13 +38,21 @@ func main() {
 	loadingPanel, _ = ui.NewLoadingPanel()
 	cursor = &ui.Cursor{}
 
-	result, _ := client.GetTrendingRepository("", "")
+	result, err := client.GetTrendingRepository("", "")
+	if err != nil {
+		result = &lib.Result{}
+	}
 	repositoryPanel.Result = result
 
 	repositoryPanel.DrawView(g)
 	textPanel.DrawView(g)
 	statusPanel.DrawView(g)
-	textPanel.DrawText(g, &repositoryPanel.Result.Items[0])
+	if err != nil {
+		statusPanel.DrawText(g, "Failed to get trending repositories.")
+	}
+	if len(repositoryPanel.Result.Items) != 0 {
+		textPanel.DrawText(g, &repositoryPanel.Result.Items[0])
+	}
 	g.SetCurrentView(repositoryPanel.ViewName)
 
 	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
@@ -141,9 +146,19 @@ func cursorMovement(d int) func(g *gocui.Gui, v *gocui.View) error {
 	}
 }
 
-func copyCloneCommand(g *gocui.Gui, _ *gocui.View) error {
+func selectedItem(g *gocui.Gui) (lib.Item, bool) {
 	yOffset, yCurrent, _ := cursor.FindPosition(g, repositoryPanel.ViewName)
-	currentItem := repositoryPanel.Result.Items[yCurrent+yOffset]
+	if yCurrent+yOffset >= len(repositoryPanel.Result.Items) {
+		return lib.Item{}, false
+	}
+	return repositoryPanel.Result.Items[yCurrent+yOffset], true
+}
+
+func copyCloneCommand(g *gocui.Gui, _ *gocui.View) error {
+	currentItem, ok := selectedItem(g)
+	if !ok {
+		return nil
+	}
 
 	err := clipboard.WriteAll("git clone " + currentItem.GetCloneURL())
 	if err != nil {
@@ -156,24 +171,14 @@ func copyCloneCommand(g *gocui.Gui, _ *gocui.View) error {
 }
 
 func openBrowser(g *gocui.Gui, _ *gocui.View) error {
-	yOffset, yCurrent, _ := cursor.FindPosition(g, repositoryPanel.ViewName)
-	currentItem := repositoryPanel.Result.Items[yCurrent+yOffset]
-	url := currentItem.GetRepositoryURL()
-
-	var err error
-
-	switch runtime.GOOS {
-	case "linux":
-		err = exec.Command("xdg-open", url).Start()
-	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		err = exec.Command("open", url).Start()
-	default:
-		statusPanel.DrawText(g, "Failed to open URL. Unsupported platform.")
+	currentItem, ok := selectedItem(g)
+	if !ok {
+		return nil
 	}
-	if err != nil {
+	url := currentItem.GetRepositoryURL()
+	if err := currentItem.OpenInBrowser(); err != nil {
 		statusPanel.DrawText(g, "Failed to open URL.")
+		return nil
 	}
 	statusPanel.DrawText(g, "Success to open URL. "+url)
 	return nil
@@ -188,15 +193,23 @@ func drawSearchEditor(g *gocui.Gui, _ *gocui.View) error {
 }
 
 func drawReadme(g *gocui.Gui, _ *gocui.View) error {
-	yOffset, yCurrent, _ := cursor.FindPosition(g, repositoryPanel.ViewName)
-	currentItem := repositoryPanel.Result.Items[yCurrent+yOffset]
+	currentItem, ok := selectedItem(g)
+	if !ok {
+		return nil
+	}
 	loadingPanel.ShowLoading(g, func() {
 		readme, err := client.GetReadme(currentItem)
-		if err != nil {
+		if lib.IsNotFound(err) {
+			statusPanel.DrawText(g, "README not found.")
+		} else if err != nil {
 			statusPanel.DrawText(g, "Failed to download README.")
 		} else {
-			b, _ := base64.StdEncoding.DecodeString(readme.Content)
-			textPanel.DrawReadme(g, &currentItem, string(b))
+			content, err := readme.DecodedContent()
+			if err != nil {
+				statusPanel.DrawText(g, "Failed to decode README.")
+				return
+			}
+			textPanel.DrawReadme(g, &currentItem, content)
 			g.SetCurrentView(textPanel.ViewName)
 		}
 	})
@@ -216,10 +229,11 @@ func searchRepositoryByTopic(g *gocui.Gui, v *gocui.View) error {
 	}
 	g.DeleteView(searchPanel.ViewName)
 	loadingPanel.ShowLoading(g, func() {
-		repositoryPanel.Result, err = client.SearchRepository(topic)
+		result, err := client.SearchRepository(topic)
 		if err != nil {
 			statusPanel.DrawText(g, "Failed to search repositories.")
 		} else {
+			repositoryPanel.Result = result
 			vr.Clear()
 			vr.Title = " Search [" + topic + "]"
 			repositoryPanel.Result.Draw(vr)